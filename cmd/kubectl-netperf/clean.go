@@ -0,0 +1,36 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+)
+
+func cleanCommand(args []string) error {
+	var opts options
+	var selector string
+	var all bool
+	flags := flag.NewFlagSet("clean", flag.ExitOnError)
+	opts.addFlags(flags)
+	flags.StringVar(&selector, "l", "", "label selector of the Netperf objects")
+	flags.BoolVar(&all, "all", false, "delete also tests that didn't finish yet")
+	flags.Parse(args)
+
+	c, namespace, err := opts.newClient()
+	if err != nil {
+		return err
+	}
+	netperfs, err := getNetperfs(c, namespace, flags.Args(), selector)
+	if err != nil {
+		return err
+	}
+	for _, netperf := range netperfs {
+		if !all && !isFinished(&netperf) {
+			continue
+		}
+		if err := c.DeleteNetperf(namespace, netperf.Name); err != nil {
+			return err
+		}
+		fmt.Printf("netperf %s deleted\n", netperf.Name)
+	}
+	return nil
+}
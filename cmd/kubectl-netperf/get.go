@@ -0,0 +1,51 @@
+package main
+
+import (
+	"flag"
+	"os"
+
+	"github.com/piontec/netperf-operator/pkg/apis/app/v1alpha1"
+	"github.com/piontec/netperf-operator/pkg/client"
+	"github.com/piontec/netperf-operator/pkg/report"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// getNetperfs returns the named Netperf objects or all the objects matching the selector
+func getNetperfs(c *client.Client, namespace string, names []string, selector string) ([]v1alpha1.Netperf, error) {
+	if len(names) == 0 {
+		list, err := c.ListNetperfs(namespace, metav1.ListOptions{LabelSelector: selector})
+		if err != nil {
+			return nil, err
+		}
+		return list.Items, nil
+	}
+	var netperfs []v1alpha1.Netperf
+	for _, name := range names {
+		netperf, err := c.GetNetperf(namespace, name)
+		if err != nil {
+			return nil, err
+		}
+		netperfs = append(netperfs, *netperf)
+	}
+	return netperfs, nil
+}
+
+func getCommand(args []string) error {
+	var opts options
+	var output, selector string
+	flags := flag.NewFlagSet("get", flag.ExitOnError)
+	opts.addFlags(flags)
+	flags.StringVar(&output, "o", report.FormatTable, "output format: table, json or csv")
+	flags.StringVar(&selector, "l", "", "label selector of the Netperf objects")
+	flags.Parse(args)
+
+	c, namespace, err := opts.newClient()
+	if err != nil {
+		return err
+	}
+	netperfs, err := getNetperfs(c, namespace, flags.Args(), selector)
+	if err != nil {
+		return err
+	}
+	return report.WriteResults(os.Stdout, output, netperfs)
+}
@@ -0,0 +1,64 @@
+// kubectl-netperf is a kubectl plugin for running Netperf tests and reporting their results
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/piontec/netperf-operator/pkg/client"
+)
+
+const usage = `Run and report network performance tests of the netperf-operator.
+
+Usage:
+  kubectl netperf run --client-node NODE --server-node NODE [flags]
+  kubectl netperf get [NAME...] [flags]
+  kubectl netperf report [NAME...] [-o html|markdown] [--matrix NAME,...] [flags]
+  kubectl netperf matrix NAME [flags]
+  kubectl netperf clean [NAME...] [flags]
+
+Use "kubectl netperf COMMAND -h" for flags of the command.
+`
+
+type command func(args []string) error
+
+var commands = map[string]command{
+	"run":    runCommand,
+	"get":    getCommand,
+	"report": reportCommand,
+	"matrix": matrixCommand,
+	"clean":  cleanCommand,
+}
+
+// options are common to all the commands
+type options struct {
+	kubeconfig string
+	namespace  string
+}
+
+func (o *options) addFlags(flags *flag.FlagSet) {
+	flags.StringVar(&o.kubeconfig, "kubeconfig", "", "path to the kubeconfig file")
+	flags.StringVar(&o.namespace, "namespace", "", "namespace of the tests, defaults to the one of the current context")
+	flags.StringVar(&o.namespace, "n", "", "shorthand for --namespace")
+}
+
+func (o *options) newClient() (*client.Client, string, error) {
+	return client.NewFromKubeconfig(o.kubeconfig, o.namespace)
+}
+
+func main() {
+	if len(os.Args) < 2 {
+		fmt.Fprint(os.Stderr, usage)
+		os.Exit(2)
+	}
+	cmd, found := commands[os.Args[1]]
+	if !found {
+		fmt.Fprintf(os.Stderr, "unknown command %q\n\n%s", os.Args[1], usage)
+		os.Exit(2)
+	}
+	if err := cmd(os.Args[2:]); err != nil {
+		fmt.Fprintf(os.Stderr, "error: %v\n", err)
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,31 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/piontec/netperf-operator/pkg/report"
+)
+
+func matrixCommand(args []string) error {
+	var opts options
+	var output string
+	flags := flag.NewFlagSet("matrix", flag.ExitOnError)
+	opts.addFlags(flags)
+	flags.StringVar(&output, "o", report.FormatTable, "output format: table or csv")
+	flags.Parse(args)
+	if flags.NArg() != 1 {
+		return fmt.Errorf("name of the NetperfMatrix is required")
+	}
+
+	c, namespace, err := opts.newClient()
+	if err != nil {
+		return err
+	}
+	matrix, err := c.GetNetperfMatrix(namespace, flags.Arg(0))
+	if err != nil {
+		return err
+	}
+	return report.WriteMatrix(os.Stdout, output, matrix)
+}
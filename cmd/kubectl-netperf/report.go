@@ -0,0 +1,57 @@
+package main
+
+import (
+	"flag"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/piontec/netperf-operator/pkg/report"
+)
+
+func reportCommand(args []string) error {
+	var opts options
+	var output, selector, matrices, title, file string
+	flags := flag.NewFlagSet("report", flag.ExitOnError)
+	opts.addFlags(flags)
+	flags.StringVar(&output, "o", report.FormatHTML, "output format: html, markdown or table, json and csv with only the test results")
+	flags.StringVar(&selector, "l", "", "label selector of the Netperf objects")
+	flags.StringVar(&matrices, "matrix", "", "comma separated names of NetperfMatrix objects to include")
+	flags.StringVar(&title, "title", "Network performance report", "title of the report")
+	flags.StringVar(&file, "file", "", "write the report to the file instead of the standard output")
+	flags.Parse(args)
+
+	c, namespace, err := opts.newClient()
+	if err != nil {
+		return err
+	}
+	r := &report.Report{Title: title, Generated: time.Now()}
+	// with only matrices requested, don't add all the tests of the namespace
+	if flags.NArg() > 0 || selector != "" || matrices == "" {
+		if r.Netperfs, err = getNetperfs(c, namespace, flags.Args(), selector); err != nil {
+			return err
+		}
+	}
+	for _, name := range strings.Split(matrices, ",") {
+		if name == "" {
+			continue
+		}
+		matrix, err := c.GetNetperfMatrix(namespace, name)
+		if err != nil {
+			return err
+		}
+		r.Matrices = append(r.Matrices, *matrix)
+	}
+
+	var w io.Writer = os.Stdout
+	if file != "" {
+		f, err := os.Create(file)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		w = f
+	}
+	return r.Write(w, output)
+}
@@ -0,0 +1,89 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/piontec/netperf-operator/pkg/apis/app/v1alpha1"
+	"github.com/piontec/netperf-operator/pkg/report"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const pollInterval = 2 * time.Second
+
+func runCommand(args []string) error {
+	var opts options
+	var name, output string
+	var wait bool
+	var timeout time.Duration
+	spec := v1alpha1.NetperfSpec{}
+	flags := flag.NewFlagSet("run", flag.ExitOnError)
+	opts.addFlags(flags)
+	flags.StringVar(&name, "name", "", "name of the Netperf object, generated if not set")
+	flags.StringVar(&spec.ClientNode, "client-node", "", "node to run the client pod on, any node if not set")
+	flags.StringVar(&spec.ServerNode, "server-node", "", "node to run the server pod on, any node if not set")
+	flags.StringVar(&spec.Backend, "backend", "", "netperf (default), iperf3, latency, http, dns or mtu")
+	flags.IntVar(&spec.ParallelStreams, "streams", 0, "number of parallel netperf streams")
+	flags.StringVar(&spec.Direction, "direction", "", "clientToServer, serverToClient or bidirectional")
+	flags.StringVar(&spec.NetworkPolicy, "network-policy", "", "Allow or Enforced")
+	flags.IntVar(&spec.TimeoutSeconds, "timeout-seconds", 0, "fail the test if it doesn't finish in time")
+	flags.BoolVar(&wait, "wait", true, "wait for the test to finish")
+	flags.DurationVar(&timeout, "timeout", 10*time.Minute, "how long to wait for the test")
+	flags.StringVar(&output, "o", report.FormatTable, "output format of the result: table, json or csv")
+	flags.Parse(args)
+
+	c, namespace, err := opts.newClient()
+	if err != nil {
+		return err
+	}
+	netperf := &v1alpha1.Netperf{
+		TypeMeta: metav1.TypeMeta{
+			Kind:       "Netperf",
+			APIVersion: "app.example.com/v1alpha1",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: namespace,
+		},
+		Spec: spec,
+	}
+	if name == "" {
+		netperf.GenerateName = "netperf-"
+	}
+	if netperf, err = c.CreateNetperf(netperf); err != nil {
+		return err
+	}
+	fmt.Fprintf(os.Stderr, "netperf %s/%s created\n", netperf.Namespace, netperf.Name)
+	if !wait {
+		return nil
+	}
+
+	status := netperf.Status.Status
+	deadline := time.Now().Add(timeout)
+	for !isFinished(netperf) {
+		if time.Now().After(deadline) {
+			return fmt.Errorf("netperf %s didn't finish within %v", netperf.Name, timeout)
+		}
+		time.Sleep(pollInterval)
+		if netperf, err = c.GetNetperf(namespace, netperf.Name); err != nil {
+			return err
+		}
+		if netperf.Status.Status != status {
+			status = netperf.Status.Status
+			fmt.Fprintf(os.Stderr, "%s %s\n", time.Now().Format("15:04:05"), status)
+		}
+	}
+	if err := report.WriteResults(os.Stdout, output, []v1alpha1.Netperf{*netperf}); err != nil {
+		return err
+	}
+	if netperf.Status.Status == v1alpha1.NetperfPhaseError || netperf.Status.Verdict == v1alpha1.NetperfVerdictFail {
+		return fmt.Errorf("netperf %s failed", netperf.Name)
+	}
+	return nil
+}
+
+func isFinished(netperf *v1alpha1.Netperf) bool {
+	return netperf.Status.Status == v1alpha1.NetperfPhaseDone || netperf.Status.Status == v1alpha1.NetperfPhaseError
+}
@@ -2,7 +2,10 @@ package main
 
 import (
 	"context"
+	"os"
 	"runtime"
+	"strconv"
+	"strings"
 
 	"github.com/piontec/netperf-operator/pkg/netperf-operator"
 
@@ -10,6 +13,9 @@ import (
 	k8sutil "github.com/operator-framework/operator-sdk/pkg/util/k8sutil"
 	sdkVersion "github.com/operator-framework/operator-sdk/version"
 	"github.com/piontec/netperf-operator/pkg/apis/app/realkube"
+	"github.com/piontec/netperf-operator/pkg/export"
+	"github.com/piontec/netperf-operator/pkg/metrics"
+	"github.com/piontec/netperf-operator/pkg/notify"
 	stub "github.com/piontec/netperf-operator/pkg/stub"
 
 	"github.com/sirupsen/logrus"
@@ -17,6 +23,8 @@ import (
 
 const version = "0.1.3-dev"
 
+const defaultMetricsAddress = ":8383"
+
 func printVersion() {
 	logrus.Infof("Go Version: %s", runtime.Version())
 	logrus.Infof("Go OS/Arch: %s/%s", runtime.GOOS, runtime.GOARCH)
@@ -24,20 +32,109 @@ func printVersion() {
 	logrus.Infof("Netperf-operator Version: %v", version)
 }
 
+func getMetricsAddress() string {
+	if address, found := os.LookupEnv("METRICS_ADDRESS"); found {
+		return address
+	}
+	return defaultMetricsAddress
+}
+
+// getDefaultTTL returns the default ttlSecondsAfterFinished of Netperf objects, nil if they
+// shouldn't be deleted by default
+func getDefaultTTL() *int32 {
+	value, found := os.LookupEnv("TTL_SECONDS_AFTER_FINISHED")
+	if !found || value == "" {
+		return nil
+	}
+	ttl, err := strconv.ParseInt(value, 10, 32)
+	if err != nil || ttl < 0 {
+		logrus.Fatalf("Invalid TTL_SECONDS_AFTER_FINISHED value %q", value)
+	}
+	result := int32(ttl)
+	return &result
+}
+
+// getNotifier returns the webhook configured with the WEBHOOK_URL and optional WEBHOOK_TEMPLATE
+// environment variables, nil if notifications are disabled
+func getNotifier() notify.Notifier {
+	url := os.Getenv("WEBHOOK_URL")
+	if url == "" {
+		return nil
+	}
+	webhook, err := notify.NewWebhook(url, os.Getenv("WEBHOOK_TEMPLATE"))
+	if err != nil {
+		logrus.Fatalf("Invalid WEBHOOK_TEMPLATE: %v", err)
+	}
+	return webhook
+}
+
+// getExporters returns exporters enabled by the EXPORT_* environment variables
+func getExporters() []export.Exporter {
+	var exporters []export.Exporter
+	if path := os.Getenv("EXPORT_FILE"); path != "" {
+		exporters = append(exporters, export.NewFileExporter(path))
+	}
+	if url := os.Getenv("EXPORT_INFLUXDB_URL"); url != "" {
+		exporters = append(exporters, export.NewInfluxDBExporter(url))
+	}
+	if url := os.Getenv("EXPORT_REMOTE_WRITE_URL"); url != "" {
+		exporters = append(exporters, export.NewRemoteWriteExporter(url))
+	}
+	if endpoint := os.Getenv("EXPORT_S3_ENDPOINT"); endpoint != "" {
+		exporters = append(exporters, export.NewS3Exporter(endpoint, os.Getenv("EXPORT_S3_BUCKET"),
+			os.Getenv("EXPORT_S3_REGION"), os.Getenv("EXPORT_S3_PREFIX"), os.Getenv("AWS_ACCESS_KEY_ID"),
+			os.Getenv("AWS_SECRET_ACCESS_KEY")))
+	}
+	for _, exporter := range exporters {
+		logrus.Infof("Exporting results to %s", exporter.Name())
+	}
+	return exporters
+}
+
+// getWatchNamespaces returns namespaces from the comma separated WATCH_NAMESPACE list. An empty
+// value means all the namespaces, which are watched using the "" namespace.
+func getWatchNamespaces() []string {
+	value, err := k8sutil.GetWatchNamespace()
+	if err != nil {
+		logrus.Fatalf("Failed to get watch namespace: %v", err)
+	}
+	var namespaces []string
+	for _, namespace := range strings.Split(value, ",") {
+		if namespace = strings.TrimSpace(namespace); namespace != "" {
+			namespaces = append(namespaces, namespace)
+		}
+	}
+	if len(namespaces) == 0 {
+		return []string{""}
+	}
+	return namespaces
+}
+
 func main() {
 	logrus.SetLevel(logrus.DebugLevel)
 	printVersion()
 
 	resource := "app.example.com/v1alpha1"
 	kind := "Netperf"
-	namespace, err := k8sutil.GetWatchNamespace()
-	if err != nil {
-		logrus.Fatalf("Failed to get watch namespace: %v", err)
-	}
 	resyncPeriod := 5
-	logrus.Infof("Watching %s, %s, %s, %d", resource, kind, namespace, resyncPeriod)
-	sdk.Watch(resource, kind, namespace, resyncPeriod)
-	sdk.Watch("v1", "Pod", namespace, resyncPeriod)
-	sdk.Handle(stub.NewHandler(operator.NewNetperf(realkube.NewRealProvider())))
+	go metrics.Serve(getMetricsAddress())
+	for _, namespace := range getWatchNamespaces() {
+		if namespace == "" {
+			logrus.Infof("Watching %s, %s, all namespaces, %d", resource, kind, resyncPeriod)
+		} else {
+			logrus.Infof("Watching %s, %s, %s, %d", resource, kind, namespace, resyncPeriod)
+		}
+		sdk.Watch(resource, kind, namespace, resyncPeriod)
+		sdk.Watch(resource, "NetperfMatrix", namespace, resyncPeriod)
+		sdk.Watch(resource, "NetperfSchedule", namespace, resyncPeriod)
+		sdk.Watch("v1", "Pod", namespace, resyncPeriod)
+	}
+	config := operator.Config{
+		DefaultTTL: getDefaultTTL(),
+		Notifier:   getNotifier(),
+		Exporters:  getExporters(),
+	}
+	sdk.Handle(stub.NewHandler(operator.NewNetperf(realkube.NewRealProvider(),
+		realkube.NewRealRecorder("netperf-operator"), config)))
 	sdk.Run(context.TODO())
 }
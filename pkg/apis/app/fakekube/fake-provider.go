@@ -2,11 +2,16 @@ package fakekube
 
 import (
 	"github.com/piontec/netperf-operator/pkg/apis/app/kube"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/client-go/kubernetes"
 )
 
+// FakeProvider keeps the objects passed to Create, Update and Delete
 type FakeProvider struct {
+	Created []runtime.Object
+	Updated []runtime.Object
+	Deleted []runtime.Object
 }
 
 func NewFakeProvider() kube.Provider {
@@ -14,10 +19,12 @@ func NewFakeProvider() kube.Provider {
 }
 
 func (r *FakeProvider) Create(object runtime.Object) error {
+	r.Created = append(r.Created, object)
 	return nil
 }
 
 func (r *FakeProvider) Update(object runtime.Object) error {
+	r.Updated = append(r.Updated, object)
 	return nil
 }
 
@@ -26,6 +33,11 @@ func (r *FakeProvider) Get(object runtime.Object) error {
 }
 
 func (r *FakeProvider) Delete(object runtime.Object) error {
+	r.Deleted = append(r.Deleted, object)
+	return nil
+}
+
+func (r *FakeProvider) List(namespace string, object runtime.Object, options metav1.ListOptions) error {
 	return nil
 }
 
@@ -0,0 +1,24 @@
+package fakekube
+
+import (
+	"fmt"
+
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+// FakeRecorder keeps recorded events as "<type> <reason> <message>" strings
+type FakeRecorder struct {
+	Events []string
+}
+
+func NewFakeRecorder() *FakeRecorder {
+	return &FakeRecorder{}
+}
+
+func (r *FakeRecorder) Event(object runtime.Object, eventType, reason, message string) {
+	r.Events = append(r.Events, fmt.Sprintf("%s %s %s", eventType, reason, message))
+}
+
+func (r *FakeRecorder) Eventf(object runtime.Object, eventType, reason, messageFmt string, args ...interface{}) {
+	r.Event(object, eventType, reason, fmt.Sprintf(messageFmt, args...))
+}
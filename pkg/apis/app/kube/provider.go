@@ -2,11 +2,13 @@ package kube
 
 import "k8s.io/client-go/kubernetes"
 import "k8s.io/apimachinery/pkg/runtime"
+import metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
 type Provider interface {
 	Create(object runtime.Object) error
 	Update(object runtime.Object) error
 	Get(object runtime.Object) error
 	Delete(object runtime.Object) error
+	List(namespace string, object runtime.Object, options metav1.ListOptions) error
 	GetKubeClient() kubernetes.Interface
 }
@@ -0,0 +1,9 @@
+package kube
+
+import "k8s.io/apimachinery/pkg/runtime"
+
+// EventRecorder records Kubernetes Events about objects handled by the operator
+type EventRecorder interface {
+	Event(object runtime.Object, eventType, reason, message string)
+	Eventf(object runtime.Object, eventType, reason, messageFmt string, args ...interface{})
+}
@@ -4,6 +4,7 @@ import (
 	"github.com/operator-framework/operator-sdk/pkg/k8sclient"
 	"github.com/operator-framework/operator-sdk/pkg/sdk"
 	"github.com/piontec/netperf-operator/pkg/apis/app/kube"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/client-go/kubernetes"
 )
@@ -31,6 +32,10 @@ func (r *RealProvider) Delete(object runtime.Object) error {
 	return sdk.Delete(object)
 }
 
+func (r *RealProvider) List(namespace string, object runtime.Object, options metav1.ListOptions) error {
+	return sdk.List(namespace, object, sdk.WithListOptions(&options))
+}
+
 func (r *RealProvider) GetKubeClient() kubernetes.Interface {
 	return k8sclient.GetKubeClient()
 }
@@ -0,0 +1,27 @@
+package realkube
+
+import (
+	"github.com/operator-framework/operator-sdk/pkg/k8sclient"
+	"github.com/piontec/netperf-operator/pkg/apis/app/kube"
+	"github.com/piontec/netperf-operator/pkg/apis/app/v1alpha1"
+	"github.com/sirupsen/logrus"
+	"k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/scheme"
+	typedcorev1 "k8s.io/client-go/kubernetes/typed/core/v1"
+	"k8s.io/client-go/tools/record"
+)
+
+// NewRealRecorder returns a recorder, that sends Events to the API server as the given component
+func NewRealRecorder(component string) kube.EventRecorder {
+	s := runtime.NewScheme()
+	scheme.AddToScheme(s)
+	v1alpha1.AddToScheme(s)
+
+	broadcaster := record.NewBroadcaster()
+	broadcaster.StartLogging(logrus.Debugf)
+	broadcaster.StartRecordingToSink(&typedcorev1.EventSinkImpl{
+		Interface: k8sclient.GetKubeClient().CoreV1().Events(""),
+	})
+	return broadcaster.NewRecorder(s, v1.EventSource{Component: component})
+}
@@ -29,6 +29,10 @@ func addKnownTypes(scheme *runtime.Scheme) error {
 	scheme.AddKnownTypes(SchemeGroupVersion,
 		&Netperf{},
 		&NetperfList{},
+		&NetperfMatrix{},
+		&NetperfMatrixList{},
+		&NetperfSchedule{},
+		&NetperfScheduleList{},
 	)
 	metav1.AddToGroupVersion(scheme, SchemeGroupVersion)
 	return nil
@@ -12,6 +12,63 @@ const (
 	NetperfPhaseError   = "Test finished with error"
 )
 
+const (
+	NetperfNetworkPolicyNone     = ""
+	NetperfNetworkPolicyAllow    = "Allow"
+	NetperfNetworkPolicyEnforced = "Enforced"
+)
+
+const (
+	NetperfMatrixPhaseInitial = ""
+	NetperfMatrixPhaseRunning = "Running"
+	NetperfMatrixPhaseDone    = "Done"
+	NetperfMatrixPhaseError   = "Failed to start"
+
+	NetperfMatrixPairPending = "Pending"
+	NetperfMatrixPairStarted = "Started"
+)
+
+const (
+	NetperfConcurrencyAllow   = "Allow"
+	NetperfConcurrencyForbid  = "Forbid"
+	NetperfConcurrencyReplace = "Replace"
+)
+
+const (
+	NetperfCleanupAlways    = "Always"
+	NetperfCleanupOnSuccess = "OnSuccess"
+	NetperfCleanupNever     = "Never"
+)
+
+const (
+	NetperfVerdictPass = "Pass"
+	NetperfVerdictFail = "Fail"
+
+	// NetperfConditionPassed is true if the last run met all the expectations
+	NetperfConditionPassed = "Passed"
+	// NetperfConditionRegressed is true if the last run was worse than its baseline
+	NetperfConditionRegressed = "Regressed"
+)
+
+const (
+	NetperfBackendNetperf = "netperf"
+	NetperfBackendIperf3  = "iperf3"
+	NetperfBackendLatency = "latency"
+	NetperfBackendHTTP    = "http"
+	NetperfBackendDNS     = "dns"
+	NetperfBackendMTU     = "mtu"
+
+	NetperfLatencyModeICMP     = "icmp"
+	NetperfLatencyModeTCP      = "tcp"
+	NetperfLatencyModeSockperf = "sockperf"
+)
+
+const (
+	NetperfDirectionClientToServer = "clientToServer"
+	NetperfDirectionServerToClient = "serverToClient"
+	NetperfDirectionBidirectional  = "bidirectional"
+)
+
 // +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
 
 type NetperfList struct {
@@ -32,10 +89,431 @@ type Netperf struct {
 type NetperfSpec struct {
 	ServerNode string `json:"serverNode"`
 	ClientNode string `json:"clientNode"`
+	// ServerNamespace and ClientNamespace are namespaces of the test pods, the namespace
+	// of the Netperf object by default
+	ServerNamespace string `json:"serverNamespace,omitempty"`
+	ClientNamespace string `json:"clientNamespace,omitempty"`
+	// NetworkPolicy is one of "", "Allow" or "Enforced". "Allow" creates NetworkPolicies that let
+	// the client reach the server in namespaces with default-deny policies. "Enforced" first runs
+	// the test without any policy, then repeats it with the policies in place.
+	NetworkPolicy string `json:"networkPolicy,omitempty"`
+	// ParallelStreams is the number of netperf instances run concurrently in the client pod
+	ParallelStreams int `json:"parallelStreams,omitempty"`
+	// Direction is one of "clientToServer" (default), "serverToClient" or "bidirectional"
+	Direction string `json:"direction,omitempty"`
+	// HistoryLimit is the number of results kept in status.history, 10 by default
+	HistoryLimit int `json:"historyLimit,omitempty"`
+	// CleanupPolicy is one of "Always", "OnSuccess" (default) or "Never" and defines when
+	// the test pods are deleted after the test is finished
+	CleanupPolicy string `json:"cleanupPolicy,omitempty"`
+	// PodTTLSecondsAfterFinished is the time after which pods kept by the CleanupPolicy are deleted
+	PodTTLSecondsAfterFinished *int32 `json:"podTTLSecondsAfterFinished,omitempty"`
+	// TimeoutSeconds fails the test if it doesn't finish in time. There's no timeout if it's not set.
+	TimeoutSeconds int `json:"timeoutSeconds,omitempty"`
+	// TTLSecondsAfterFinished is the time after which the finished Netperf object is deleted.
+	// If it's not set, the default of the operator is used.
+	TTLSecondsAfterFinished *int32 `json:"ttlSecondsAfterFinished,omitempty"`
+	// Expectations are checked after the test to compute its verdict
+	Expectations *NetperfExpectations `json:"expectations,omitempty"`
+	// Baseline the results are compared with to detect regressions
+	Baseline *NetperfBaseline `json:"baseline,omitempty"`
+	// Backend is the tool running the test, "netperf" (default), "iperf3", "latency", "http",
+	// "dns" or "mtu"
+	Backend string `json:"backend,omitempty"`
+	// Iperf3 has options of the "iperf3" backend
+	Iperf3 *NetperfIperf3Spec `json:"iperf3,omitempty"`
+	// Latency has options of the "latency" backend
+	Latency *NetperfLatencySpec `json:"latency,omitempty"`
+	// HTTP has options of the "http" backend
+	HTTP *NetperfHTTPSpec `json:"http,omitempty"`
+	// DNS has options of the "dns" backend
+	DNS *NetperfDNSSpec `json:"dns,omitempty"`
+	// Sidecar runs the test pods with a service mesh sidecar
+	Sidecar *NetperfSidecarSpec `json:"sidecar,omitempty"`
+}
+
+// MeasuresThroughput tells if the backend of the test measures throughput. The other backends
+// leave speedBitsPerSec at 0 and report latency or the path MTU.
+func (s *NetperfSpec) MeasuresThroughput() bool {
+	switch s.Backend {
+	case NetperfBackendLatency, NetperfBackendHTTP, NetperfBackendDNS, NetperfBackendMTU:
+		return false
+	}
+	return true
+}
+
+// NetperfSidecarSpec sets the metadata controlling sidecar injection of the test pods. By default
+// it's the "sidecar.istio.io/inject" label.
+type NetperfSidecarSpec struct {
+	// Compare first runs the test without the sidecar, then with it and reports the overhead
+	Compare bool `json:"compare,omitempty"`
+	// WithSidecar is set on pods that get the sidecar
+	WithSidecar NetperfPodMetadata `json:"withSidecar,omitempty"`
+	// WithoutSidecar is set on pods that don't get the sidecar in the "compare" mode
+	WithoutSidecar NetperfPodMetadata `json:"withoutSidecar,omitempty"`
+}
+
+type NetperfPodMetadata struct {
+	Labels      map[string]string `json:"labels,omitempty"`
+	Annotations map[string]string `json:"annotations,omitempty"`
+}
+
+type NetperfDNSSpec struct {
+	// Names are resolved in turns, "kubernetes.default.svc.cluster.local" by default
+	Names []string `json:"names,omitempty"`
+	// QPS is the target rate of queries, 10 by default
+	QPS int `json:"qps,omitempty"`
+	// Count is the number of queries, 100 by default
+	Count int `json:"count,omitempty"`
+	// Server is the IP of the DNS server, like a node-local cache. The cluster DNS is used if
+	// it's not set. Search domains aren't used with a custom server, so names have to be fully qualified.
+	Server string `json:"server,omitempty"`
+}
+
+type NetperfHTTPSpec struct {
+	// QPS is the target rate of requests, 100 by default
+	QPS int `json:"qps,omitempty"`
+	// Connections is the number of concurrent connections, 1 by default
+	Connections int `json:"connections,omitempty"`
+	// DurationSeconds is the length of the test, 10 seconds by default
+	DurationSeconds int `json:"durationSeconds,omitempty"`
+	// Path of the requested URL, "/" by default
+	Path string `json:"path,omitempty"`
+	// ViaService sends the requests to a Service selecting the server pod instead of the pod IP
+	ViaService bool `json:"viaService,omitempty"`
 }
+
+type NetperfLatencySpec struct {
+	// Mode is one of "icmp" (default) for ping, "tcp" for TCP connect probes or "sockperf"
+	// for sockperf ping-pong
+	Mode string `json:"mode,omitempty"`
+	// Count is the number of ping and TCP connect probes, 100 by default
+	Count int `json:"count,omitempty"`
+	// IntervalMilliseconds is the time between ping and TCP connect probes, 200 by default
+	IntervalMilliseconds int `json:"intervalMilliseconds,omitempty"`
+	// DurationSeconds is the length of the sockperf test, 10 seconds by default
+	DurationSeconds int `json:"durationSeconds,omitempty"`
+	// Image of the test pods, it has to provide sockperf in the "sockperf" mode
+	Image string `json:"image,omitempty"`
+}
+
+type NetperfIperf3Spec struct {
+	// UDP runs the test over UDP instead of TCP
+	UDP bool `json:"udp,omitempty"`
+	// Bandwidth is the target bitrate passed to iperf3 -b, like "100M". UDP tests default to 1 Mbit/s.
+	Bandwidth string `json:"bandwidth,omitempty"`
+	// DurationSeconds is the length of the test, 10 seconds by default
+	DurationSeconds int `json:"durationSeconds,omitempty"`
+}
+
+// NetperfBaseline sets where the baseline results come from: the last result of another Netperf,
+// stored values or the average of the last runs in the history of this Netperf, in this order.
+type NetperfBaseline struct {
+	Netperf                string  `json:"netperf,omitempty"`
+	SpeedBitsPerSec        float64 `json:"speedBitsPerSec,omitempty"`
+	P99LatencyMicroseconds float64 `json:"p99LatencyMicroseconds,omitempty"`
+	HistoryRuns            int     `json:"historyRuns,omitempty"`
+	// MaxThroughputDropPercent is the allowed throughput drop, 10% by default
+	MaxThroughputDropPercent float64 `json:"maxThroughputDropPercent,omitempty"`
+	// MaxLatencyIncreasePercent is the allowed p99 latency increase, it's not checked if not set
+	MaxLatencyIncreasePercent float64 `json:"maxLatencyIncreasePercent,omitempty"`
+}
+
+// NetperfExpectations define thresholds the test results have to meet to pass. Unset
+// thresholds aren't checked.
+type NetperfExpectations struct {
+	MinThroughputBitsPerSec   float64 `json:"minThroughputBitsPerSec,omitempty"`
+	MaxP99LatencyMicroseconds float64 `json:"maxP99LatencyMicroseconds,omitempty"`
+	MaxRetransmits            *int64  `json:"maxRetransmits,omitempty"`
+	// MaxCPUPercent applies to CPU utilization of both the client and the server node
+	MaxCPUPercent float64 `json:"maxCPUPercent,omitempty"`
+}
+
 type NetperfStatus struct {
 	Status          string  `json:"status"`
 	ServerPod       string  `json:"serverPod"`
 	ClientPod       string  `json:"clientPod"`
 	SpeedBitsPerSec float64 `json:"speedBitsPerSec"`
+	// PolicyEnforced is true once the NetworkPolicies for the test are created
+	PolicyEnforced bool `json:"policyEnforced,omitempty"`
+	// UnenforcedSpeedBitsPerSec is the throughput measured without NetworkPolicies in the "Enforced" mode
+	UnenforcedSpeedBitsPerSec float64 `json:"unenforcedSpeedBitsPerSec,omitempty"`
+	// Streams has per stream results if ParallelStreams is set, SpeedBitsPerSec is their sum then
+	Streams []NetperfStreamResult `json:"streams,omitempty"`
+	// Fairness compares the streams, in the "bidirectional" mode it's computed for each direction
+	// separately instead
+	Fairness *NetperfFairness `json:"fairness,omitempty"`
+	// throughput per direction, SpeedBitsPerSec is their sum in the "bidirectional" mode
+	ClientToServerBitsPerSec float64          `json:"clientToServerBitsPerSec,omitempty"`
+	ServerToClientBitsPerSec float64          `json:"serverToClientBitsPerSec,omitempty"`
+	ClientToServerFairness   *NetperfFairness `json:"clientToServerFairness,omitempty"`
+	ServerToClientFairness   *NetperfFairness `json:"serverToClientFairness,omitempty"`
+	// Run is the number of the current run, it's increased each time the test is re-run
+	Run int `json:"run,omitempty"`
+	// Rerun is the value of the re-run annotation that triggered the current run
+	Rerun          string       `json:"rerun,omitempty"`
+	StartTime      *metav1.Time `json:"startTime,omitempty"`
+	CompletionTime *metav1.Time `json:"completionTime,omitempty"`
+	// nodes the test pods were run on
+	ServerNode string `json:"serverNode,omitempty"`
+	ClientNode string `json:"clientNode,omitempty"`
+	// SidecarInjected is true once the test pods are created with the sidecar in the "compare" mode
+	SidecarInjected bool `json:"sidecarInjected,omitempty"`
+	// Sidecar compares the results with the results of the run without the sidecar
+	Sidecar *NetperfSidecarResult `json:"sidecar,omitempty"`
+	// CleanedUp is true once pods of the finished test are deleted
+	CleanedUp bool `json:"cleanedUp,omitempty"`
+	// Stats are additional measurements, collected only if the expectations need them
+	Stats *NetperfStats `json:"stats,omitempty"`
+	// Verdict is "Pass" or "Fail" if the test has expectations
+	Verdict string `json:"verdict,omitempty"`
+	// Regression compares the results with the baseline
+	Regression *NetperfRegression `json:"regression,omitempty"`
+	Conditions []NetperfCondition `json:"conditions,omitempty"`
+	// UDP has datagram statistics of UDP tests
+	UDP *NetperfUDPResult `json:"udp,omitempty"`
+	// Intervals has throughput of the reporting intervals of iperf3 tests
+	Intervals *NetperfIntervalsResult `json:"intervals,omitempty"`
+	// Latency has the round trip time distribution measured by the "latency" backend
+	Latency *NetperfLatencyResult `json:"latency,omitempty"`
+	// HTTP has results of the "http" backend
+	HTTP *NetperfHTTPResult `json:"http,omitempty"`
+	// DNS has results of the "dns" backend
+	DNS *NetperfDNSResult `json:"dns,omitempty"`
+	// MTU has results of the "mtu" backend
+	MTU *NetperfMTUResult `json:"mtu,omitempty"`
+	// Output is the name of the ConfigMap with raw output of the test pods
+	Output string `json:"output,omitempty"`
+	// History has results of the previous runs, the newest one last
+	History []NetperfRunResult `json:"history,omitempty"`
+}
+
+type NetperfRunResult struct {
+	Run                      int         `json:"run"`
+	Status                   string      `json:"status"`
+	StartTime                metav1.Time `json:"startTime,omitempty"`
+	CompletionTime           metav1.Time `json:"completionTime,omitempty"`
+	ServerNode               string      `json:"serverNode,omitempty"`
+	ClientNode               string      `json:"clientNode,omitempty"`
+	TestType                 string      `json:"testType"`
+	ParallelStreams          int         `json:"parallelStreams,omitempty"`
+	SpeedBitsPerSec          float64     `json:"speedBitsPerSec"`
+	ClientToServerBitsPerSec float64     `json:"clientToServerBitsPerSec,omitempty"`
+	ServerToClientBitsPerSec float64     `json:"serverToClientBitsPerSec,omitempty"`
+	P99LatencyMicroseconds   float64     `json:"p99LatencyMicroseconds,omitempty"`
+	Verdict                  string      `json:"verdict,omitempty"`
+}
+
+// NetperfRegression has the baseline values and relative changes of the results, positive
+// changes are improvements
+type NetperfRegression struct {
+	Baseline                       string  `json:"baseline"`
+	BaselineSpeedBitsPerSec        float64 `json:"baselineSpeedBitsPerSec,omitempty"`
+	ThroughputChangePercent        float64 `json:"throughputChangePercent"`
+	BaselineP99LatencyMicroseconds float64 `json:"baselineP99LatencyMicroseconds,omitempty"`
+	LatencyChangePercent           float64 `json:"latencyChangePercent,omitempty"`
+	Regressed                      bool    `json:"regressed"`
+}
+
+// NetperfStats are aggregated over all the streams: the worst latency and CPU utilization
+// and the sum of retransmits
+type NetperfStats struct {
+	P99LatencyMicroseconds float64 `json:"p99LatencyMicroseconds,omitempty"`
+	Retransmits            int64   `json:"retransmits"`
+	ClientCPUPercent       float64 `json:"clientCPUPercent,omitempty"`
+	ServerCPUPercent       float64 `json:"serverCPUPercent,omitempty"`
+}
+
+type NetperfUDPResult struct {
+	JitterMilliseconds float64 `json:"jitterMilliseconds"`
+	LostPackets        int64   `json:"lostPackets"`
+	Packets            int64   `json:"packets"`
+	LostPercent        float64 `json:"lostPercent"`
+}
+
+// NetperfIntervalsResult shows how stable the throughput was during the test
+type NetperfIntervalsResult struct {
+	MinBitsPerSec float64 `json:"minBitsPerSec"`
+	MaxBitsPerSec float64 `json:"maxBitsPerSec"`
+	// Series has at most 60 first intervals, the following ones are only included in min and max
+	Series []NetperfInterval `json:"series,omitempty"`
+}
+
+type NetperfInterval struct {
+	StartSeconds    float64 `json:"startSeconds"`
+	EndSeconds      float64 `json:"endSeconds"`
+	SpeedBitsPerSec float64 `json:"speedBitsPerSec"`
+}
+
+// NetperfLatencyResult has round trip times of the probes
+type NetperfLatencyResult struct {
+	MinMicroseconds float64 `json:"minMicroseconds"`
+	AvgMicroseconds float64 `json:"avgMicroseconds"`
+	P99Microseconds float64 `json:"p99Microseconds"`
+	MaxMicroseconds float64 `json:"maxMicroseconds"`
+	// JitterMicroseconds is the mean difference of consecutive round trip times, sockperf
+	// doesn't report it
+	JitterMicroseconds float64 `json:"jitterMicroseconds"`
+	// StdDevMicroseconds is the standard deviation of round trip times
+	StdDevMicroseconds float64 `json:"stdDevMicroseconds"`
+	Sent               int64   `json:"sent"`
+	Received           int64   `json:"received"`
+	LossPercent        float64 `json:"lossPercent"`
+}
+
+type NetperfHTTPResult struct {
+	TargetQPS       float64 `json:"targetQPS"`
+	ActualQPS       float64 `json:"actualQPS"`
+	Requests        int64   `json:"requests"`
+	Errors          int64   `json:"errors"`
+	ErrorPercent    float64 `json:"errorPercent"`
+	AvgMicroseconds float64 `json:"avgMicroseconds"`
+	P50Microseconds float64 `json:"p50Microseconds"`
+	P90Microseconds float64 `json:"p90Microseconds"`
+	P99Microseconds float64 `json:"p99Microseconds"`
+	MaxMicroseconds float64 `json:"maxMicroseconds"`
+}
+
+type NetperfDNSResult struct {
+	TargetQPS float64 `json:"targetQPS"`
+	// ActualQPS is the rate of queries over the whole test, queries are sent one by one
+	ActualQPS       float64 `json:"actualQPS"`
+	Queries         int64   `json:"queries"`
+	Failures        int64   `json:"failures"`
+	FailurePercent  float64 `json:"failurePercent"`
+	MinMicroseconds float64 `json:"minMicroseconds"`
+	AvgMicroseconds float64 `json:"avgMicroseconds"`
+	P50Microseconds float64 `json:"p50Microseconds"`
+	P99Microseconds float64 `json:"p99Microseconds"`
+	MaxMicroseconds float64 `json:"maxMicroseconds"`
+}
+
+type NetperfMTUResult struct {
+	// PathMTU is the largest packet that reached the server with the don't fragment bit set
+	PathMTU            int `json:"pathMTU"`
+	ClientInterfaceMTU int `json:"clientInterfaceMTU"`
+	ServerInterfaceMTU int `json:"serverInterfaceMTU,omitempty"`
+	// Mismatch is true if the path MTU is lower than the MTU of the pod interfaces, so packets
+	// the pods send are dropped or fragmented
+	Mismatch bool `json:"mismatch"`
+	// FragmentationWorks is true if packets of the interface MTU size reach the server when
+	// they're allowed to be fragmented
+	FragmentationWorks bool `json:"fragmentationWorks"`
+}
+
+// NetperfSidecarResult has the overhead of the sidecar, positive values mean the test was slower with it
+type NetperfSidecarResult struct {
+	WithoutSidecarSpeedBitsPerSec        float64 `json:"withoutSidecarSpeedBitsPerSec"`
+	WithoutSidecarP99LatencyMicroseconds float64 `json:"withoutSidecarP99LatencyMicroseconds,omitempty"`
+	ThroughputOverheadPercent            float64 `json:"throughputOverheadPercent"`
+	LatencyOverheadMicroseconds          float64 `json:"latencyOverheadMicroseconds,omitempty"`
+	LatencyOverheadPercent               float64 `json:"latencyOverheadPercent,omitempty"`
+}
+
+type NetperfCondition struct {
+	Type               string      `json:"type"`
+	Status             string      `json:"status"`
+	LastTransitionTime metav1.Time `json:"lastTransitionTime,omitempty"`
+	Reason             string      `json:"reason,omitempty"`
+	Message            string      `json:"message,omitempty"`
+}
+
+type NetperfStreamResult struct {
+	Stream          int     `json:"stream"`
+	Direction       string  `json:"direction,omitempty"`
+	SpeedBitsPerSec float64 `json:"speedBitsPerSec"`
+}
+
+// NetperfFairness describes how evenly the throughput was shared between parallel streams
+type NetperfFairness struct {
+	MinBitsPerSec    float64 `json:"minBitsPerSec"`
+	MaxBitsPerSec    float64 `json:"maxBitsPerSec"`
+	MeanBitsPerSec   float64 `json:"meanBitsPerSec"`
+	StdDevBitsPerSec float64 `json:"stdDevBitsPerSec"`
+	// JainIndex is Jain's fairness index: 1 when all streams got the same throughput, 1/n in the worst case
+	JainIndex float64 `json:"jainIndex"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+type NetperfMatrixList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata"`
+	Items           []NetperfMatrix `json:"items"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// NetperfMatrix runs a Netperf test for each ordered pair of the selected nodes
+type NetperfMatrix struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata"`
+	Spec              NetperfMatrixSpec   `json:"spec"`
+	Status            NetperfMatrixStatus `json:"status,omitempty"`
+}
+
+type NetperfMatrixSpec struct {
+	// NodeSelector selects nodes to test, all nodes are used if it's empty
+	NodeSelector map[string]string `json:"nodeSelector,omitempty"`
+	// MaxPairs limits the number of tested node pairs to a random sample, 0 means all pairs
+	MaxPairs int `json:"maxPairs,omitempty"`
+	// Concurrency is the number of Netperf tests run at the same time, 1 by default
+	Concurrency int `json:"concurrency,omitempty"`
+	// Template is the spec of created Netperf objects, serverNode and clientNode are set for each pair
+	Template NetperfSpec `json:"template,omitempty"`
+}
+
+type NetperfMatrixStatus struct {
+	Status  string                `json:"status"`
+	Nodes   []string              `json:"nodes,omitempty"`
+	Results []NetperfMatrixResult `json:"results,omitempty"`
+}
+
+type NetperfMatrixResult struct {
+	ServerNode             string  `json:"serverNode"`
+	ClientNode             string  `json:"clientNode"`
+	Netperf                string  `json:"netperf,omitempty"`
+	Status                 string  `json:"status"`
+	SpeedBitsPerSec        float64 `json:"speedBitsPerSec"`
+	P99LatencyMicroseconds float64 `json:"p99LatencyMicroseconds,omitempty"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+type NetperfScheduleList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata"`
+	Items           []NetperfSchedule `json:"items"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// NetperfSchedule creates a new Netperf object on each tick of its cron schedule
+type NetperfSchedule struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata"`
+	Spec              NetperfScheduleSpec   `json:"spec"`
+	Status            NetperfScheduleStatus `json:"status,omitempty"`
+}
+
+type NetperfScheduleSpec struct {
+	// Schedule is a cron expression, like "0 2 * * *"
+	Schedule string `json:"schedule"`
+	// ConcurrencyPolicy is one of "Allow" (default), "Forbid" or "Replace" and works like in CronJobs
+	ConcurrencyPolicy string `json:"concurrencyPolicy,omitempty"`
+	// HistoryLimit is the number of finished Netperf objects to keep, 3 by default
+	HistoryLimit *int `json:"historyLimit,omitempty"`
+	Suspend      bool `json:"suspend,omitempty"`
+	// StartingDeadlineSeconds skips ticks missed by more than the deadline, like in CronJobs
+	StartingDeadlineSeconds *int64 `json:"startingDeadlineSeconds,omitempty"`
+	// Template is the spec of created Netperf objects
+	Template NetperfSpec `json:"template"`
+}
+
+type NetperfScheduleStatus struct {
+	LastScheduleTime *metav1.Time `json:"lastScheduleTime,omitempty"`
+	Active           []string     `json:"active,omitempty"`
+	LastNetperf      string       `json:"lastNetperf,omitempty"`
+	// Message explains why the schedule doesn't run, it's empty if it runs
+	Message string `json:"message,omitempty"`
 }
@@ -5,6 +5,7 @@
 package v1alpha1
 
 import (
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	runtime "k8s.io/apimachinery/pkg/runtime"
 )
 
@@ -13,8 +14,8 @@ func (in *Netperf) DeepCopyInto(out *Netperf) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
-	out.Spec = in.Spec
-	out.Status = in.Status
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
 	return
 }
 
@@ -37,6 +38,234 @@ func (in *Netperf) DeepCopyObject() runtime.Object {
 	}
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NetperfBaseline) DeepCopyInto(out *NetperfBaseline) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NetperfBaseline.
+func (in *NetperfBaseline) DeepCopy() *NetperfBaseline {
+	if in == nil {
+		return nil
+	}
+	out := new(NetperfBaseline)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NetperfCondition) DeepCopyInto(out *NetperfCondition) {
+	*out = *in
+	in.LastTransitionTime.DeepCopyInto(&out.LastTransitionTime)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NetperfCondition.
+func (in *NetperfCondition) DeepCopy() *NetperfCondition {
+	if in == nil {
+		return nil
+	}
+	out := new(NetperfCondition)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NetperfDNSResult) DeepCopyInto(out *NetperfDNSResult) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NetperfDNSResult.
+func (in *NetperfDNSResult) DeepCopy() *NetperfDNSResult {
+	if in == nil {
+		return nil
+	}
+	out := new(NetperfDNSResult)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NetperfDNSSpec) DeepCopyInto(out *NetperfDNSSpec) {
+	*out = *in
+	if in.Names != nil {
+		in, out := &in.Names, &out.Names
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NetperfDNSSpec.
+func (in *NetperfDNSSpec) DeepCopy() *NetperfDNSSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(NetperfDNSSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NetperfExpectations) DeepCopyInto(out *NetperfExpectations) {
+	*out = *in
+	if in.MaxRetransmits != nil {
+		in, out := &in.MaxRetransmits, &out.MaxRetransmits
+		if *in == nil {
+			*out = nil
+		} else {
+			*out = new(int64)
+			**out = **in
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NetperfExpectations.
+func (in *NetperfExpectations) DeepCopy() *NetperfExpectations {
+	if in == nil {
+		return nil
+	}
+	out := new(NetperfExpectations)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NetperfFairness) DeepCopyInto(out *NetperfFairness) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NetperfFairness.
+func (in *NetperfFairness) DeepCopy() *NetperfFairness {
+	if in == nil {
+		return nil
+	}
+	out := new(NetperfFairness)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NetperfHTTPResult) DeepCopyInto(out *NetperfHTTPResult) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NetperfHTTPResult.
+func (in *NetperfHTTPResult) DeepCopy() *NetperfHTTPResult {
+	if in == nil {
+		return nil
+	}
+	out := new(NetperfHTTPResult)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NetperfHTTPSpec) DeepCopyInto(out *NetperfHTTPSpec) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NetperfHTTPSpec.
+func (in *NetperfHTTPSpec) DeepCopy() *NetperfHTTPSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(NetperfHTTPSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NetperfInterval) DeepCopyInto(out *NetperfInterval) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NetperfInterval.
+func (in *NetperfInterval) DeepCopy() *NetperfInterval {
+	if in == nil {
+		return nil
+	}
+	out := new(NetperfInterval)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NetperfIntervalsResult) DeepCopyInto(out *NetperfIntervalsResult) {
+	*out = *in
+	if in.Series != nil {
+		in, out := &in.Series, &out.Series
+		*out = make([]NetperfInterval, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NetperfIntervalsResult.
+func (in *NetperfIntervalsResult) DeepCopy() *NetperfIntervalsResult {
+	if in == nil {
+		return nil
+	}
+	out := new(NetperfIntervalsResult)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NetperfIperf3Spec) DeepCopyInto(out *NetperfIperf3Spec) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NetperfIperf3Spec.
+func (in *NetperfIperf3Spec) DeepCopy() *NetperfIperf3Spec {
+	if in == nil {
+		return nil
+	}
+	out := new(NetperfIperf3Spec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NetperfLatencyResult) DeepCopyInto(out *NetperfLatencyResult) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NetperfLatencyResult.
+func (in *NetperfLatencyResult) DeepCopy() *NetperfLatencyResult {
+	if in == nil {
+		return nil
+	}
+	out := new(NetperfLatencyResult)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NetperfLatencySpec) DeepCopyInto(out *NetperfLatencySpec) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NetperfLatencySpec.
+func (in *NetperfLatencySpec) DeepCopy() *NetperfLatencySpec {
+	if in == nil {
+		return nil
+	}
+	out := new(NetperfLatencySpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *NetperfList) DeepCopyInto(out *NetperfList) {
 	*out = *in
@@ -71,9 +300,461 @@ func (in *NetperfList) DeepCopyObject() runtime.Object {
 	}
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NetperfMTUResult) DeepCopyInto(out *NetperfMTUResult) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NetperfMTUResult.
+func (in *NetperfMTUResult) DeepCopy() *NetperfMTUResult {
+	if in == nil {
+		return nil
+	}
+	out := new(NetperfMTUResult)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NetperfMatrix) DeepCopyInto(out *NetperfMatrix) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NetperfMatrix.
+func (in *NetperfMatrix) DeepCopy() *NetperfMatrix {
+	if in == nil {
+		return nil
+	}
+	out := new(NetperfMatrix)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *NetperfMatrix) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	} else {
+		return nil
+	}
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NetperfMatrixList) DeepCopyInto(out *NetperfMatrixList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	out.ListMeta = in.ListMeta
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]NetperfMatrix, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NetperfMatrixList.
+func (in *NetperfMatrixList) DeepCopy() *NetperfMatrixList {
+	if in == nil {
+		return nil
+	}
+	out := new(NetperfMatrixList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *NetperfMatrixList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	} else {
+		return nil
+	}
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NetperfMatrixResult) DeepCopyInto(out *NetperfMatrixResult) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NetperfMatrixResult.
+func (in *NetperfMatrixResult) DeepCopy() *NetperfMatrixResult {
+	if in == nil {
+		return nil
+	}
+	out := new(NetperfMatrixResult)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NetperfMatrixSpec) DeepCopyInto(out *NetperfMatrixSpec) {
+	*out = *in
+	if in.NodeSelector != nil {
+		in, out := &in.NodeSelector, &out.NodeSelector
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	in.Template.DeepCopyInto(&out.Template)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NetperfMatrixSpec.
+func (in *NetperfMatrixSpec) DeepCopy() *NetperfMatrixSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(NetperfMatrixSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NetperfMatrixStatus) DeepCopyInto(out *NetperfMatrixStatus) {
+	*out = *in
+	if in.Nodes != nil {
+		in, out := &in.Nodes, &out.Nodes
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Results != nil {
+		in, out := &in.Results, &out.Results
+		*out = make([]NetperfMatrixResult, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NetperfMatrixStatus.
+func (in *NetperfMatrixStatus) DeepCopy() *NetperfMatrixStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(NetperfMatrixStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NetperfPodMetadata) DeepCopyInto(out *NetperfPodMetadata) {
+	*out = *in
+	if in.Labels != nil {
+		in, out := &in.Labels, &out.Labels
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.Annotations != nil {
+		in, out := &in.Annotations, &out.Annotations
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NetperfPodMetadata.
+func (in *NetperfPodMetadata) DeepCopy() *NetperfPodMetadata {
+	if in == nil {
+		return nil
+	}
+	out := new(NetperfPodMetadata)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NetperfRegression) DeepCopyInto(out *NetperfRegression) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NetperfRegression.
+func (in *NetperfRegression) DeepCopy() *NetperfRegression {
+	if in == nil {
+		return nil
+	}
+	out := new(NetperfRegression)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NetperfRunResult) DeepCopyInto(out *NetperfRunResult) {
+	*out = *in
+	in.StartTime.DeepCopyInto(&out.StartTime)
+	in.CompletionTime.DeepCopyInto(&out.CompletionTime)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NetperfRunResult.
+func (in *NetperfRunResult) DeepCopy() *NetperfRunResult {
+	if in == nil {
+		return nil
+	}
+	out := new(NetperfRunResult)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NetperfSchedule) DeepCopyInto(out *NetperfSchedule) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NetperfSchedule.
+func (in *NetperfSchedule) DeepCopy() *NetperfSchedule {
+	if in == nil {
+		return nil
+	}
+	out := new(NetperfSchedule)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *NetperfSchedule) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	} else {
+		return nil
+	}
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NetperfScheduleList) DeepCopyInto(out *NetperfScheduleList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	out.ListMeta = in.ListMeta
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]NetperfSchedule, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NetperfScheduleList.
+func (in *NetperfScheduleList) DeepCopy() *NetperfScheduleList {
+	if in == nil {
+		return nil
+	}
+	out := new(NetperfScheduleList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *NetperfScheduleList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	} else {
+		return nil
+	}
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NetperfScheduleSpec) DeepCopyInto(out *NetperfScheduleSpec) {
+	*out = *in
+	if in.HistoryLimit != nil {
+		in, out := &in.HistoryLimit, &out.HistoryLimit
+		if *in == nil {
+			*out = nil
+		} else {
+			*out = new(int)
+			**out = **in
+		}
+	}
+	if in.StartingDeadlineSeconds != nil {
+		in, out := &in.StartingDeadlineSeconds, &out.StartingDeadlineSeconds
+		if *in == nil {
+			*out = nil
+		} else {
+			*out = new(int64)
+			**out = **in
+		}
+	}
+	in.Template.DeepCopyInto(&out.Template)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NetperfScheduleSpec.
+func (in *NetperfScheduleSpec) DeepCopy() *NetperfScheduleSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(NetperfScheduleSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NetperfScheduleStatus) DeepCopyInto(out *NetperfScheduleStatus) {
+	*out = *in
+	if in.LastScheduleTime != nil {
+		in, out := &in.LastScheduleTime, &out.LastScheduleTime
+		if *in == nil {
+			*out = nil
+		} else {
+			*out = new(v1.Time)
+			(*in).DeepCopyInto(*out)
+		}
+	}
+	if in.Active != nil {
+		in, out := &in.Active, &out.Active
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NetperfScheduleStatus.
+func (in *NetperfScheduleStatus) DeepCopy() *NetperfScheduleStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(NetperfScheduleStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NetperfSidecarResult) DeepCopyInto(out *NetperfSidecarResult) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NetperfSidecarResult.
+func (in *NetperfSidecarResult) DeepCopy() *NetperfSidecarResult {
+	if in == nil {
+		return nil
+	}
+	out := new(NetperfSidecarResult)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NetperfSidecarSpec) DeepCopyInto(out *NetperfSidecarSpec) {
+	*out = *in
+	in.WithSidecar.DeepCopyInto(&out.WithSidecar)
+	in.WithoutSidecar.DeepCopyInto(&out.WithoutSidecar)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NetperfSidecarSpec.
+func (in *NetperfSidecarSpec) DeepCopy() *NetperfSidecarSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(NetperfSidecarSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *NetperfSpec) DeepCopyInto(out *NetperfSpec) {
 	*out = *in
+	if in.PodTTLSecondsAfterFinished != nil {
+		in, out := &in.PodTTLSecondsAfterFinished, &out.PodTTLSecondsAfterFinished
+		if *in == nil {
+			*out = nil
+		} else {
+			*out = new(int32)
+			**out = **in
+		}
+	}
+	if in.TTLSecondsAfterFinished != nil {
+		in, out := &in.TTLSecondsAfterFinished, &out.TTLSecondsAfterFinished
+		if *in == nil {
+			*out = nil
+		} else {
+			*out = new(int32)
+			**out = **in
+		}
+	}
+	if in.Expectations != nil {
+		in, out := &in.Expectations, &out.Expectations
+		if *in == nil {
+			*out = nil
+		} else {
+			*out = new(NetperfExpectations)
+			(*in).DeepCopyInto(*out)
+		}
+	}
+	if in.Baseline != nil {
+		in, out := &in.Baseline, &out.Baseline
+		if *in == nil {
+			*out = nil
+		} else {
+			*out = new(NetperfBaseline)
+			**out = **in
+		}
+	}
+	if in.Iperf3 != nil {
+		in, out := &in.Iperf3, &out.Iperf3
+		if *in == nil {
+			*out = nil
+		} else {
+			*out = new(NetperfIperf3Spec)
+			**out = **in
+		}
+	}
+	if in.Latency != nil {
+		in, out := &in.Latency, &out.Latency
+		if *in == nil {
+			*out = nil
+		} else {
+			*out = new(NetperfLatencySpec)
+			**out = **in
+		}
+	}
+	if in.HTTP != nil {
+		in, out := &in.HTTP, &out.HTTP
+		if *in == nil {
+			*out = nil
+		} else {
+			*out = new(NetperfHTTPSpec)
+			**out = **in
+		}
+	}
+	if in.DNS != nil {
+		in, out := &in.DNS, &out.DNS
+		if *in == nil {
+			*out = nil
+		} else {
+			*out = new(NetperfDNSSpec)
+			(*in).DeepCopyInto(*out)
+		}
+	}
+	if in.Sidecar != nil {
+		in, out := &in.Sidecar, &out.Sidecar
+		if *in == nil {
+			*out = nil
+		} else {
+			*out = new(NetperfSidecarSpec)
+			(*in).DeepCopyInto(*out)
+		}
+	}
 	return
 }
 
@@ -87,9 +768,170 @@ func (in *NetperfSpec) DeepCopy() *NetperfSpec {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NetperfStats) DeepCopyInto(out *NetperfStats) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NetperfStats.
+func (in *NetperfStats) DeepCopy() *NetperfStats {
+	if in == nil {
+		return nil
+	}
+	out := new(NetperfStats)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *NetperfStatus) DeepCopyInto(out *NetperfStatus) {
 	*out = *in
+	if in.Streams != nil {
+		in, out := &in.Streams, &out.Streams
+		*out = make([]NetperfStreamResult, len(*in))
+		copy(*out, *in)
+	}
+	if in.Fairness != nil {
+		in, out := &in.Fairness, &out.Fairness
+		if *in == nil {
+			*out = nil
+		} else {
+			*out = new(NetperfFairness)
+			**out = **in
+		}
+	}
+	if in.ClientToServerFairness != nil {
+		in, out := &in.ClientToServerFairness, &out.ClientToServerFairness
+		if *in == nil {
+			*out = nil
+		} else {
+			*out = new(NetperfFairness)
+			**out = **in
+		}
+	}
+	if in.ServerToClientFairness != nil {
+		in, out := &in.ServerToClientFairness, &out.ServerToClientFairness
+		if *in == nil {
+			*out = nil
+		} else {
+			*out = new(NetperfFairness)
+			**out = **in
+		}
+	}
+	if in.StartTime != nil {
+		in, out := &in.StartTime, &out.StartTime
+		if *in == nil {
+			*out = nil
+		} else {
+			*out = new(v1.Time)
+			(*in).DeepCopyInto(*out)
+		}
+	}
+	if in.CompletionTime != nil {
+		in, out := &in.CompletionTime, &out.CompletionTime
+		if *in == nil {
+			*out = nil
+		} else {
+			*out = new(v1.Time)
+			(*in).DeepCopyInto(*out)
+		}
+	}
+	if in.Sidecar != nil {
+		in, out := &in.Sidecar, &out.Sidecar
+		if *in == nil {
+			*out = nil
+		} else {
+			*out = new(NetperfSidecarResult)
+			**out = **in
+		}
+	}
+	if in.Stats != nil {
+		in, out := &in.Stats, &out.Stats
+		if *in == nil {
+			*out = nil
+		} else {
+			*out = new(NetperfStats)
+			**out = **in
+		}
+	}
+	if in.Regression != nil {
+		in, out := &in.Regression, &out.Regression
+		if *in == nil {
+			*out = nil
+		} else {
+			*out = new(NetperfRegression)
+			**out = **in
+		}
+	}
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]NetperfCondition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.UDP != nil {
+		in, out := &in.UDP, &out.UDP
+		if *in == nil {
+			*out = nil
+		} else {
+			*out = new(NetperfUDPResult)
+			**out = **in
+		}
+	}
+	if in.Intervals != nil {
+		in, out := &in.Intervals, &out.Intervals
+		if *in == nil {
+			*out = nil
+		} else {
+			*out = new(NetperfIntervalsResult)
+			(*in).DeepCopyInto(*out)
+		}
+	}
+	if in.Latency != nil {
+		in, out := &in.Latency, &out.Latency
+		if *in == nil {
+			*out = nil
+		} else {
+			*out = new(NetperfLatencyResult)
+			**out = **in
+		}
+	}
+	if in.HTTP != nil {
+		in, out := &in.HTTP, &out.HTTP
+		if *in == nil {
+			*out = nil
+		} else {
+			*out = new(NetperfHTTPResult)
+			**out = **in
+		}
+	}
+	if in.DNS != nil {
+		in, out := &in.DNS, &out.DNS
+		if *in == nil {
+			*out = nil
+		} else {
+			*out = new(NetperfDNSResult)
+			**out = **in
+		}
+	}
+	if in.MTU != nil {
+		in, out := &in.MTU, &out.MTU
+		if *in == nil {
+			*out = nil
+		} else {
+			*out = new(NetperfMTUResult)
+			**out = **in
+		}
+	}
+	if in.History != nil {
+		in, out := &in.History, &out.History
+		*out = make([]NetperfRunResult, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 	return
 }
 
@@ -102,3 +944,35 @@ func (in *NetperfStatus) DeepCopy() *NetperfStatus {
 	in.DeepCopyInto(out)
 	return out
 }
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NetperfStreamResult) DeepCopyInto(out *NetperfStreamResult) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NetperfStreamResult.
+func (in *NetperfStreamResult) DeepCopy() *NetperfStreamResult {
+	if in == nil {
+		return nil
+	}
+	out := new(NetperfStreamResult)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NetperfUDPResult) DeepCopyInto(out *NetperfUDPResult) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NetperfUDPResult.
+func (in *NetperfUDPResult) DeepCopy() *NetperfUDPResult {
+	if in == nil {
+		return nil
+	}
+	out := new(NetperfUDPResult)
+	in.DeepCopyInto(out)
+	return out
+}
@@ -0,0 +1,87 @@
+// Package client is a typed client of the Netperf API for command line tools
+package client
+
+import (
+	"github.com/piontec/netperf-operator/pkg/apis/app/v1alpha1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/serializer"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/clientcmd"
+)
+
+const (
+	netperfResource       = "netperfs"
+	netperfMatrixResource = "netperfmatrices"
+)
+
+type Client struct {
+	rest rest.Interface
+}
+
+// NewFromKubeconfig loads the configuration the same way kubectl does: from the given file,
+// $KUBECONFIG or ~/.kube/config. It returns the client and the namespace to use, namespace
+// overrides the one set in the current context.
+func NewFromKubeconfig(kubeconfig, namespace string) (*Client, string, error) {
+	rules := clientcmd.NewDefaultClientConfigLoadingRules()
+	rules.ExplicitPath = kubeconfig
+	overrides := &clientcmd.ConfigOverrides{}
+	overrides.Context.Namespace = namespace
+	clientConfig := clientcmd.NewNonInteractiveDeferredLoadingClientConfig(rules, overrides)
+	config, err := clientConfig.ClientConfig()
+	if err != nil {
+		return nil, "", err
+	}
+	ns, _, err := clientConfig.Namespace()
+	if err != nil {
+		return nil, "", err
+	}
+	client, err := NewForConfig(config)
+	return client, ns, err
+}
+
+func NewForConfig(config *rest.Config) (*Client, error) {
+	scheme := runtime.NewScheme()
+	if err := v1alpha1.AddToScheme(scheme); err != nil {
+		return nil, err
+	}
+	c := *config
+	c.GroupVersion = &v1alpha1.SchemeGroupVersion
+	c.APIPath = "/apis"
+	c.ContentType = runtime.ContentTypeJSON
+	c.NegotiatedSerializer = serializer.DirectCodecFactory{CodecFactory: serializer.NewCodecFactory(scheme)}
+	restClient, err := rest.RESTClientFor(&c)
+	if err != nil {
+		return nil, err
+	}
+	return &Client{rest: restClient}, nil
+}
+
+func (c *Client) GetNetperf(namespace, name string) (*v1alpha1.Netperf, error) {
+	result := &v1alpha1.Netperf{}
+	err := c.rest.Get().Namespace(namespace).Resource(netperfResource).Name(name).Do().Into(result)
+	return result, err
+}
+
+func (c *Client) ListNetperfs(namespace string, options metav1.ListOptions) (*v1alpha1.NetperfList, error) {
+	result := &v1alpha1.NetperfList{}
+	err := c.rest.Get().Namespace(namespace).Resource(netperfResource).
+		VersionedParams(&options, metav1.ParameterCodec).Do().Into(result)
+	return result, err
+}
+
+func (c *Client) CreateNetperf(netperf *v1alpha1.Netperf) (*v1alpha1.Netperf, error) {
+	result := &v1alpha1.Netperf{}
+	err := c.rest.Post().Namespace(netperf.Namespace).Resource(netperfResource).Body(netperf).Do().Into(result)
+	return result, err
+}
+
+func (c *Client) DeleteNetperf(namespace, name string) error {
+	return c.rest.Delete().Namespace(namespace).Resource(netperfResource).Name(name).Do().Error()
+}
+
+func (c *Client) GetNetperfMatrix(namespace, name string) (*v1alpha1.NetperfMatrix, error) {
+	result := &v1alpha1.NetperfMatrix{}
+	err := c.rest.Get().Namespace(namespace).Resource(netperfMatrixResource).Name(name).Do().Into(result)
+	return result, err
+}
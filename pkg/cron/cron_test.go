@@ -0,0 +1,80 @@
+package cron
+
+import (
+	"testing"
+	"time"
+)
+
+func TestSchedule_Next(t *testing.T) {
+	from := time.Date(2018, time.June, 13, 10, 30, 15, 0, time.UTC)
+	tests := []struct {
+		name string
+		spec string
+		want time.Time
+	}{
+		{
+			name: "Every minute",
+			spec: "* * * * *",
+			want: time.Date(2018, time.June, 13, 10, 31, 0, 0, time.UTC),
+		},
+		{
+			name: "Every 15 minutes",
+			spec: "*/15 * * * *",
+			want: time.Date(2018, time.June, 13, 10, 45, 0, 0, time.UTC),
+		},
+		{
+			name: "Nightly",
+			spec: "0 2 * * *",
+			want: time.Date(2018, time.June, 14, 2, 0, 0, 0, time.UTC),
+		},
+		{
+			name: "Daily descriptor",
+			spec: "@daily",
+			want: time.Date(2018, time.June, 14, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			name: "Weekdays by name",
+			spec: "0 9 * * sat,sun",
+			want: time.Date(2018, time.June, 16, 9, 0, 0, 0, time.UTC),
+		},
+		{
+			name: "Sunday as 7",
+			spec: "0 9 * * 7",
+			want: time.Date(2018, time.June, 17, 9, 0, 0, 0, time.UTC),
+		},
+		{
+			name: "Day of month or day of week",
+			spec: "0 0 1 * mon",
+			want: time.Date(2018, time.June, 18, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			name: "List of months",
+			spec: "0 0 1 jan,jul *",
+			want: time.Date(2018, time.July, 1, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			name: "Never",
+			spec: "0 0 30 2 *",
+			want: time.Time{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, err := Parse(tt.spec)
+			if err != nil {
+				t.Fatalf("Parse() error = %v", err)
+			}
+			if got := s.Next(from); !got.Equal(tt.want) {
+				t.Errorf("Schedule.Next() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParse_invalid(t *testing.T) {
+	for _, spec := range []string{"", "* * * *", "60 * * * *", "* * * * * *", "5-1 * * * *", "*/0 * * * *", "0 0 * * 8", "x * * * *"} {
+		if _, err := Parse(spec); err == nil {
+			t.Errorf("Parse(%q) expected error", spec)
+		}
+	}
+}
@@ -0,0 +1,71 @@
+// Package export sends results of finished Netperf tests to external storage
+package export
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"time"
+)
+
+const defaultTimeout = 30 * time.Second
+
+// bitsPerMegabit converts throughput of the Result to bits per second for the exporters
+// that name their series and fields by the unit
+const bitsPerMegabit = 1e6
+
+// Result of a successfully finished test run. Throughput is in 10^6 bits per second, as
+// reported by the tests, it's 0 for tests that measure only latency.
+type Result struct {
+	Namespace                string    `json:"namespace"`
+	Name                     string    `json:"name"`
+	Run                      int       `json:"run"`
+	Time                     time.Time `json:"time"`
+	ServerNode               string    `json:"serverNode"`
+	ClientNode               string    `json:"clientNode"`
+	TestType                 string    `json:"testType"`
+	Direction                string    `json:"direction"`
+	ParallelStreams          int       `json:"parallelStreams"`
+	SpeedBitsPerSec          float64   `json:"speedBitsPerSec"`
+	ClientToServerBitsPerSec float64   `json:"clientToServerBitsPerSec"`
+	ServerToClientBitsPerSec float64   `json:"serverToClientBitsPerSec"`
+	P99LatencyMicroseconds   float64   `json:"p99LatencyMicroseconds,omitempty"`
+	Retransmits              int64     `json:"retransmits,omitempty"`
+	Verdict                  string    `json:"verdict,omitempty"`
+}
+
+// Exporter stores the result. The operator doesn't call Export concurrently.
+type Exporter interface {
+	Name() string
+	Export(result Result) error
+}
+
+// tags returns the values identifying the test, used as labels or tags by the exporters
+func (r Result) tags() map[string]string {
+	return map[string]string{
+		"namespace":   r.Namespace,
+		"name":        r.Name,
+		"server_node": r.ServerNode,
+		"client_node": r.ClientNode,
+		"test_type":   r.TestType,
+	}
+}
+
+func newHTTPClient() *http.Client {
+	return &http.Client{Timeout: defaultTimeout}
+}
+
+// send executes the request and returns an error for non 2xx responses
+func send(client *http.Client, req *http.Request) error {
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	body, _ := ioutil.ReadAll(resp.Body)
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("%s %s returned %s: %s", req.Method, req.URL, resp.Status, bytes.TrimSpace(body))
+	}
+	return nil
+}
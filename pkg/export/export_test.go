@@ -0,0 +1,151 @@
+package export
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+var testResult = Result{
+	Namespace:       "default",
+	Name:            "example",
+	Run:             2,
+	Time:            time.Date(2018, 5, 1, 12, 0, 0, 0, time.UTC),
+	ServerNode:      "node-1",
+	ClientNode:      "node 2",
+	TestType:        "TCP_STREAM",
+	ParallelStreams: 1,
+	SpeedBitsPerSec: 9000.5,
+}
+
+type request struct {
+	method string
+	path   string
+	header http.Header
+	body   []byte
+}
+
+func newTestServer(t *testing.T, requests *[]request) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := ioutil.ReadAll(r.Body)
+		if err != nil {
+			t.Errorf("Failed to read request body: %v", err)
+		}
+		*requests = append(*requests, request{r.Method, r.URL.Path, r.Header, body})
+		w.WriteHeader(http.StatusNoContent)
+	}))
+}
+
+func TestFileExporter_Export(t *testing.T) {
+	dir, err := ioutil.TempDir("", "export")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	exporter := NewFileExporter(filepath.Join(dir, "results.jsonl"))
+	for i := 0; i < 2; i++ {
+		if err := exporter.Export(testResult); err != nil {
+			t.Fatalf("FileExporter.Export() error = %v", err)
+		}
+	}
+	f, err := os.Open(exporter.Path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	lines := 0
+	for scanner := bufio.NewScanner(f); scanner.Scan(); lines++ {
+		var result Result
+		if err := json.Unmarshal(scanner.Bytes(), &result); err != nil || result.Name != testResult.Name {
+			t.Errorf("FileExporter.Export() wrote %s, error %v", scanner.Text(), err)
+		}
+	}
+	if lines != 2 {
+		t.Errorf("FileExporter.Export() wrote %d lines, want 2", lines)
+	}
+}
+
+func TestInfluxDBExporter_Export(t *testing.T) {
+	var requests []request
+	server := newTestServer(t, &requests)
+	defer server.Close()
+
+	if err := NewInfluxDBExporter(server.URL + "/write?db=netperf").Export(testResult); err != nil {
+		t.Fatalf("InfluxDBExporter.Export() error = %v", err)
+	}
+	want := `netperf,client_node=node\ 2,name=example,namespace=default,server_node=node-1,test_type=TCP_STREAM ` +
+		"speed_bits_per_sec=9000500000,client_to_server_bits_per_sec=0,server_to_client_bits_per_sec=0," +
+		"parallel_streams=1i,run=2i 1525176000000000000\n"
+	if len(requests) != 1 || requests[0].path != "/write" || string(requests[0].body) != want {
+		t.Errorf("InfluxDBExporter.Export() sent %v, want %s", requests, want)
+	}
+}
+
+func TestInfluxDBExporter_line_latency(t *testing.T) {
+	result := testResult
+	result.TestType = "TCP_RR"
+	result.SpeedBitsPerSec = 0
+	result.P99LatencyMicroseconds = 250
+	line := NewInfluxDBExporter("").line(result)
+	if strings.Contains(line, "bits_per_sec") || !strings.Contains(line, "p99_latency_us=250") {
+		t.Errorf("InfluxDBExporter.line() = %s, want only the latency", line)
+	}
+}
+
+func TestRemoteWriteExporter_Export(t *testing.T) {
+	var requests []request
+	server := newTestServer(t, &requests)
+	defer server.Close()
+
+	if err := NewRemoteWriteExporter(server.URL + "/api/v1/write").Export(testResult); err != nil {
+		t.Fatalf("RemoteWriteExporter.Export() error = %v", err)
+	}
+	if len(requests) != 1 || requests[0].header.Get("Content-Encoding") != "snappy" {
+		t.Fatalf("RemoteWriteExporter.Export() sent %v, want one snappy encoded request", requests)
+	}
+	body := requests[0].body
+	length, n := binary.Uvarint(body)
+	// the only literal is longer than 256 bytes, so its tag is followed by 2 bytes of length
+	decoded := body[n+3:]
+	if int(length) != len(decoded) || !bytes.Equal(decoded, NewRemoteWriteExporter("").writeRequest(testResult)) {
+		t.Errorf("RemoteWriteExporter.Export() sent %d bytes, that don't decode to the write request", len(body))
+	}
+	if !bytes.Contains(decoded, []byte("netperf_result_throughput_bits_per_second")) {
+		t.Errorf("RemoteWriteExporter.Export() didn't send the throughput series")
+	}
+}
+
+func TestS3Exporter_Export(t *testing.T) {
+	var requests []request
+	server := newTestServer(t, &requests)
+	defer server.Close()
+
+	exporter := NewS3Exporter(server.URL, "results", "", "netperf/", "access", "secret")
+	if err := exporter.Export(testResult); err != nil {
+		t.Fatalf("S3Exporter.Export() error = %v", err)
+	}
+	if len(requests) != 1 {
+		t.Fatalf("S3Exporter.Export() sent %d requests, want 1", len(requests))
+	}
+	r := requests[0]
+	if r.method != http.MethodPut || r.path != "/results/netperf/default/example/1525176000-2.json" {
+		t.Errorf("S3Exporter.Export() sent %s %s", r.method, r.path)
+	}
+	if auth := r.header.Get("Authorization"); !strings.HasPrefix(auth, "AWS4-HMAC-SHA256 Credential=access/") {
+		t.Errorf("S3Exporter.Export() sent Authorization %q", auth)
+	}
+	var result Result
+	if err := json.Unmarshal(r.body, &result); err != nil || result.SpeedBitsPerSec != testResult.SpeedBitsPerSec {
+		t.Errorf("S3Exporter.Export() uploaded %s, error %v", r.body, err)
+	}
+}
@@ -0,0 +1,75 @@
+package export
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const influxMeasurement = "netperf"
+
+var influxTagEscaper = strings.NewReplacer(",", `\,`, " ", `\ `, "=", `\=`)
+
+// InfluxDBExporter writes results in the line protocol, URL is the full write endpoint,
+// e.g. http://influxdb:8086/write?db=netperf
+type InfluxDBExporter struct {
+	URL    string
+	Client *http.Client
+}
+
+func NewInfluxDBExporter(url string) *InfluxDBExporter {
+	return &InfluxDBExporter{URL: url, Client: newHTTPClient()}
+}
+
+func (e *InfluxDBExporter) Name() string {
+	return "influxdb"
+}
+
+// line formats the result as a point of the "netperf" measurement
+func (e *InfluxDBExporter) line(result Result) string {
+	tags := result.tags()
+	var keys []string
+	for key := range tags {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var line bytes.Buffer
+	line.WriteString(influxMeasurement)
+	for _, key := range keys {
+		if tags[key] == "" {
+			continue
+		}
+		fmt.Fprintf(&line, ",%s=%s", key, influxTagEscaper.Replace(tags[key]))
+	}
+	var fields []string
+	if result.SpeedBitsPerSec > 0 {
+		fields = append(fields,
+			"speed_bits_per_sec="+strconv.FormatFloat(result.SpeedBitsPerSec*bitsPerMegabit, 'f', -1, 64),
+			"client_to_server_bits_per_sec="+strconv.FormatFloat(result.ClientToServerBitsPerSec*bitsPerMegabit, 'f', -1, 64),
+			"server_to_client_bits_per_sec="+strconv.FormatFloat(result.ServerToClientBitsPerSec*bitsPerMegabit, 'f', -1, 64))
+	}
+	fields = append(fields,
+		"parallel_streams="+strconv.Itoa(result.ParallelStreams)+"i",
+		"run="+strconv.Itoa(result.Run)+"i")
+	if result.P99LatencyMicroseconds > 0 {
+		fields = append(fields, "p99_latency_us="+strconv.FormatFloat(result.P99LatencyMicroseconds, 'f', -1, 64))
+	}
+	if result.Verdict != "" {
+		fields = append(fields, "verdict="+strconv.Quote(result.Verdict))
+	}
+	fmt.Fprintf(&line, " %s %d\n", strings.Join(fields, ","), result.Time.UnixNano())
+	return line.String()
+}
+
+func (e *InfluxDBExporter) Export(result Result) error {
+	req, err := http.NewRequest(http.MethodPost, e.URL, strings.NewReader(e.line(result)))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "text/plain; charset=utf-8")
+	return send(e.Client, req)
+}
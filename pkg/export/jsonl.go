@@ -0,0 +1,35 @@
+package export
+
+import (
+	"encoding/json"
+	"os"
+)
+
+// FileExporter appends results to a file as JSON lines, e.g. on a persistent volume
+type FileExporter struct {
+	Path string
+}
+
+func NewFileExporter(path string) *FileExporter {
+	return &FileExporter{Path: path}
+}
+
+func (e *FileExporter) Name() string {
+	return "file"
+}
+
+func (e *FileExporter) Export(result Result) error {
+	line, err := json.Marshal(result)
+	if err != nil {
+		return err
+	}
+	f, err := os.OpenFile(e.Path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	if _, err = f.Write(append(line, '\n')); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
@@ -0,0 +1,140 @@
+package export
+
+import (
+	"bytes"
+	"encoding/binary"
+	"math"
+	"net/http"
+	"sort"
+)
+
+// RemoteWriteExporter pushes results as samples using the Prometheus remote write protocol.
+// The request is encoded by hand, to avoid depending on the Prometheus server code.
+type RemoteWriteExporter struct {
+	URL    string
+	Client *http.Client
+}
+
+func NewRemoteWriteExporter(url string) *RemoteWriteExporter {
+	return &RemoteWriteExporter{URL: url, Client: newHTTPClient()}
+}
+
+func (e *RemoteWriteExporter) Name() string {
+	return "remote-write"
+}
+
+type sample struct {
+	name  string
+	value float64
+}
+
+func (e *RemoteWriteExporter) samples(result Result) []sample {
+	var samples []sample
+	if result.SpeedBitsPerSec > 0 {
+		samples = append(samples,
+			sample{"netperf_result_throughput_bits_per_second", result.SpeedBitsPerSec * bitsPerMegabit},
+			sample{"netperf_result_client_to_server_bits_per_second", result.ClientToServerBitsPerSec * bitsPerMegabit},
+			sample{"netperf_result_server_to_client_bits_per_second", result.ServerToClientBitsPerSec * bitsPerMegabit})
+	}
+	if result.P99LatencyMicroseconds > 0 {
+		samples = append(samples, sample{"netperf_result_p99_latency_microseconds", result.P99LatencyMicroseconds})
+	}
+	return samples
+}
+
+// writeRequest encodes the prometheus.WriteRequest protobuf message with one time series
+// per sample
+func (e *RemoteWriteExporter) writeRequest(result Result) []byte {
+	tags := result.tags()
+	timestamp := result.Time.UnixNano() / int64(1e6)
+	var request []byte
+	for _, s := range e.samples(result) {
+		labels := map[string]string{"__name__": s.name}
+		for key, value := range tags {
+			if value != "" {
+				labels[key] = value
+			}
+		}
+		// labels have to be sorted by name
+		var names []string
+		for name := range labels {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+
+		var series []byte
+		for _, name := range names {
+			var label []byte
+			label = appendString(label, 1, name)
+			label = appendString(label, 2, labels[name])
+			series = appendBytes(series, 1, label)
+		}
+		var sampleMsg []byte
+		sampleMsg = appendVarint(sampleMsg, 1<<3|1)
+		sampleMsg = appendFixed64(sampleMsg, math.Float64bits(s.value))
+		sampleMsg = appendVarint(sampleMsg, 2<<3)
+		sampleMsg = appendVarint(sampleMsg, uint64(timestamp))
+		series = appendBytes(series, 2, sampleMsg)
+		request = appendBytes(request, 1, series)
+	}
+	return request
+}
+
+func appendVarint(b []byte, v uint64) []byte {
+	var buf [binary.MaxVarintLen64]byte
+	return append(b, buf[:binary.PutUvarint(buf[:], v)]...)
+}
+
+func appendFixed64(b []byte, v uint64) []byte {
+	var buf [8]byte
+	binary.LittleEndian.PutUint64(buf[:], v)
+	return append(b, buf[:]...)
+}
+
+// appendBytes appends a length delimited field
+func appendBytes(b []byte, field int, value []byte) []byte {
+	b = appendVarint(b, uint64(field)<<3|2)
+	b = appendVarint(b, uint64(len(value)))
+	return append(b, value...)
+}
+
+func appendString(b []byte, field int, value string) []byte {
+	return appendBytes(b, field, []byte(value))
+}
+
+// snappyEncode returns a valid snappy block consisting of literals only. It doesn't compress
+// the data, but requests are small and remote write requires the snappy format.
+func snappyEncode(data []byte) []byte {
+	const maxLiteral = 1 << 16
+	encoded := appendVarint(nil, uint64(len(data)))
+	for len(data) > 0 {
+		chunk := data
+		if len(chunk) > maxLiteral {
+			chunk = chunk[:maxLiteral]
+		}
+		n := len(chunk) - 1
+		switch {
+		case n < 60:
+			encoded = append(encoded, byte(n)<<2)
+		case n < 1<<8:
+			encoded = append(encoded, 60<<2, byte(n))
+		default:
+			encoded = append(encoded, 61<<2, byte(n), byte(n>>8))
+		}
+		encoded = append(encoded, chunk...)
+		data = data[len(chunk):]
+	}
+	return encoded
+}
+
+func (e *RemoteWriteExporter) Export(result Result) error {
+	body := snappyEncode(e.writeRequest(result))
+	req, err := http.NewRequest(http.MethodPost, e.URL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/x-protobuf")
+	req.Header.Set("Content-Encoding", "snappy")
+	req.Header.Set("X-Prometheus-Remote-Write-Version", "0.1.0")
+	return send(e.Client, req)
+}
@@ -0,0 +1,102 @@
+package export
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// S3Exporter uploads each result as a JSON object to S3 compatible storage, using path style
+// URLs and signature version 4
+type S3Exporter struct {
+	Endpoint  string
+	Bucket    string
+	Region    string
+	Prefix    string
+	AccessKey string
+	SecretKey string
+	Client    *http.Client
+}
+
+func NewS3Exporter(endpoint, bucket, region, prefix, accessKey, secretKey string) *S3Exporter {
+	if region == "" {
+		region = "us-east-1"
+	}
+	return &S3Exporter{
+		Endpoint:  strings.TrimSuffix(endpoint, "/"),
+		Bucket:    bucket,
+		Region:    region,
+		Prefix:    prefix,
+		AccessKey: accessKey,
+		SecretKey: secretKey,
+		Client:    newHTTPClient(),
+	}
+}
+
+func (e *S3Exporter) Name() string {
+	return "s3"
+}
+
+// key returns the object key "<prefix><namespace>/<name>/<unix time>-<run>.json"
+func (e *S3Exporter) key(result Result) string {
+	return fmt.Sprintf("%s%s/%s/%d-%d.json", e.Prefix, result.Namespace, result.Name, result.Time.Unix(), result.Run)
+}
+
+func (e *S3Exporter) Export(result Result) error {
+	body, err := json.Marshal(result)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequest(http.MethodPut, fmt.Sprintf("%s/%s/%s", e.Endpoint, e.Bucket, e.key(result)),
+		bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	e.sign(req, body, time.Now().UTC())
+	return send(e.Client, req)
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	h := hmac.New(sha256.New, key)
+	h.Write([]byte(data))
+	return h.Sum(nil)
+}
+
+func sha256Hex(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// sign adds the AWS signature version 4 headers to the request
+func (e *S3Exporter) sign(req *http.Request, body []byte, now time.Time) {
+	date := now.Format("20060102")
+	timestamp := now.Format("20060102T150405Z")
+	payloadHash := sha256Hex(body)
+	req.Header.Set("X-Amz-Date", timestamp)
+	req.Header.Set("X-Amz-Content-Sha256", payloadHash)
+
+	signedHeaders := "content-type;host;x-amz-content-sha256;x-amz-date"
+	canonicalHeaders := fmt.Sprintf("content-type:%s\nhost:%s\nx-amz-content-sha256:%s\nx-amz-date:%s\n",
+		req.Header.Get("Content-Type"), req.URL.Host, payloadHash, timestamp)
+	canonicalRequest := strings.Join([]string{req.Method, req.URL.EscapedPath(), req.URL.RawQuery,
+		canonicalHeaders, signedHeaders, payloadHash}, "\n")
+
+	scope := fmt.Sprintf("%s/%s/s3/aws4_request", date, e.Region)
+	stringToSign := strings.Join([]string{"AWS4-HMAC-SHA256", timestamp, scope,
+		sha256Hex([]byte(canonicalRequest))}, "\n")
+	key := hmacSHA256([]byte("AWS4"+e.SecretKey), date)
+	key = hmacSHA256(key, e.Region)
+	key = hmacSHA256(key, "s3")
+	key = hmacSHA256(key, "aws4_request")
+	signature := hex.EncodeToString(hmacSHA256(key, stringToSign))
+
+	req.Header.Set("Authorization", fmt.Sprintf("AWS4-HMAC-SHA256 Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		e.AccessKey, scope, signedHeaders, signature))
+}
@@ -0,0 +1,130 @@
+// Package metrics defines Prometheus metrics of the operator and test results
+package metrics
+
+import (
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"github.com/sirupsen/logrus"
+)
+
+const namespace = "netperf"
+
+// bitsPerMegabit converts throughput reported by the tests, in 10^6 bits per second
+const bitsPerMegabit = 1e6
+
+const (
+	OutcomeDone  = "done"
+	OutcomeError = "error"
+)
+
+var (
+	resultLabels = []string{"namespace", "name", "server_node", "client_node", "test_type"}
+
+	throughput = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "throughput_bits_per_second",
+		Help:      "Throughput measured by the last run of the Netperf test.",
+	}, resultLabels)
+
+	latencyP99 = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "latency_p99_microseconds",
+		Help:      "99th percentile latency measured by the last run of the Netperf test.",
+	}, resultLabels)
+
+	testDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "test_duration_seconds",
+		Help:      "Time from creation of the Netperf object to the end of the test.",
+		Buckets:   []float64{15, 30, 60, 120, 300, 600, 1200},
+	}, []string{"test_type"})
+
+	testsTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "tests_total",
+		Help:      "Number of finished Netperf tests by outcome.",
+	}, []string{"test_type", "outcome"})
+
+	reconcileErrors = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "reconcile_errors_total",
+		Help:      "Number of errors returned while handling events, by kind of the object.",
+	}, []string{"kind"})
+)
+
+// results keeps label values of the result gauges per Netperf, so they can be removed
+// when the Netperf object is deleted
+var results = struct {
+	sync.Mutex
+	labels map[string][]string
+}{labels: map[string][]string{}}
+
+func init() {
+	prometheus.MustRegister(throughput, latencyP99, testDuration, testsTotal, reconcileErrors)
+}
+
+// Serve exposes the metrics on the /metrics path of the address. It blocks, so it should be
+// started in a goroutine.
+func Serve(address string) {
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", promhttp.Handler())
+	logrus.Infof("Serving metrics on %s/metrics", address)
+	if err := http.ListenAndServe(address, mux); err != nil {
+		logrus.Errorf("Metrics server failed: %v", err)
+	}
+}
+
+// ObserveResult records the result of a successfully finished test. Throughput is in 10^6 bits
+// per second, as reported by the tests. Zero values weren't measured and aren't recorded.
+func ObserveResult(ns, name, serverNode, clientNode, testType string, megabitsPerSec, p99LatencyMicroseconds float64,
+	duration time.Duration) {
+	labels := []string{ns, name, serverNode, clientNode, testType}
+	key := ns + "/" + name
+	results.Lock()
+	if old, found := results.labels[key]; found {
+		deleteResult(old)
+	}
+	results.labels[key] = labels
+	results.Unlock()
+
+	if megabitsPerSec > 0 {
+		throughput.WithLabelValues(labels...).Set(megabitsPerSec * bitsPerMegabit)
+	}
+	if p99LatencyMicroseconds > 0 {
+		latencyP99.WithLabelValues(labels...).Set(p99LatencyMicroseconds)
+	}
+	ObserveOutcome(testType, OutcomeDone, duration)
+}
+
+// ObserveOutcome counts a finished test. Duration is ignored if it's zero.
+func ObserveOutcome(testType, outcome string, duration time.Duration) {
+	testsTotal.WithLabelValues(testType, outcome).Inc()
+	if duration > 0 {
+		testDuration.WithLabelValues(testType).Observe(duration.Seconds())
+	}
+}
+
+// ForgetNetperf removes results of a deleted Netperf object
+func ForgetNetperf(ns, name string) {
+	key := ns + "/" + name
+	results.Lock()
+	defer results.Unlock()
+	if old, found := results.labels[key]; found {
+		deleteResult(old)
+		delete(results.labels, key)
+	}
+}
+
+func deleteResult(labels []string) {
+	throughput.DeleteLabelValues(labels...)
+	latencyP99.DeleteLabelValues(labels...)
+}
+
+// ReconcileError counts an error returned by the event handler
+func ReconcileError(kind string) {
+	reconcileErrors.WithLabelValues(kind).Inc()
+}
@@ -0,0 +1,74 @@
+package metrics
+
+import (
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+)
+
+// gatherMetric returns the metric of the family with the label values, or nil if there's none
+func gatherMetric(t *testing.T, family string, labels map[string]string) *dto.Metric {
+	families, err := prometheus.DefaultGatherer.Gather()
+	if err != nil {
+		t.Fatalf("Gather() error = %v", err)
+	}
+	for _, f := range families {
+		if f.GetName() != family {
+			continue
+		}
+		for _, m := range f.GetMetric() {
+			matched := 0
+			for _, pair := range m.GetLabel() {
+				if value, found := labels[pair.GetName()]; found && value == pair.GetValue() {
+					matched++
+				}
+			}
+			if matched == len(labels) {
+				return m
+			}
+		}
+	}
+	return nil
+}
+
+func counterValue(t *testing.T, counter prometheus.Counter) float64 {
+	m := &dto.Metric{}
+	if err := counter.Write(m); err != nil {
+		t.Fatalf("Write() error = %v", err)
+	}
+	return m.GetCounter().GetValue()
+}
+
+func TestObserveResult(t *testing.T) {
+	labels := map[string]string{"namespace": "tests", "name": "example", "server_node": "node-1",
+		"client_node": "node-2", "test_type": "netperf"}
+	done := counterValue(t, testsTotal.WithLabelValues("netperf", OutcomeDone))
+	failed := counterValue(t, testsTotal.WithLabelValues("netperf", OutcomeError))
+
+	ObserveResult("tests", "example", "node-1", "node-2", "netperf", 9000.5, 0, time.Minute)
+	m := gatherMetric(t, "netperf_throughput_bits_per_second", labels)
+	if m == nil {
+		t.Fatalf("ObserveResult() didn't record throughput with labels %v", labels)
+	}
+	if got := m.GetGauge().GetValue(); got != 9000.5e6 {
+		t.Errorf("ObserveResult() recorded throughput %v, want %v bits per second", got, 9000.5e6)
+	}
+	if gatherMetric(t, "netperf_latency_p99_microseconds", labels) != nil {
+		t.Errorf("ObserveResult() recorded latency, that wasn't measured")
+	}
+	if got := counterValue(t, testsTotal.WithLabelValues("netperf", OutcomeDone)); got != done+1 {
+		t.Errorf("netperf_tests_total{outcome=%q} = %v, want %v", OutcomeDone, got, done+1)
+	}
+
+	ObserveOutcome("netperf", OutcomeError, 0)
+	if got := counterValue(t, testsTotal.WithLabelValues("netperf", OutcomeError)); got != failed+1 {
+		t.Errorf("netperf_tests_total{outcome=%q} = %v, want %v", OutcomeError, got, failed+1)
+	}
+
+	ForgetNetperf("tests", "example")
+	if gatherMetric(t, "netperf_throughput_bits_per_second", labels) != nil {
+		t.Errorf("ForgetNetperf() didn't remove the throughput of the deleted Netperf")
+	}
+}
@@ -0,0 +1,104 @@
+package operator
+
+import (
+	"fmt"
+
+	"github.com/piontec/netperf-operator/pkg/apis/app/v1alpha1"
+	"github.com/sirupsen/logrus"
+	"k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+)
+
+// backend is the load generator tool run in the test pods
+type backend interface {
+	// image of both the server and the client pod
+	image(cr *v1alpha1.Netperf) string
+	// serverCommand returns nil to run the default command of the image
+	serverCommand(cr *v1alpha1.Netperf) []string
+	clientCommand(cr *v1alpha1.Netperf, serverIP string) []string
+	// ports of the server pod the client connects to, used by the network policies
+	ports(cr *v1alpha1.Netperf) []networkingv1.NetworkPolicyPort
+	// testType names the test run, it's used to label results
+	testType(cr *v1alpha1.Netperf) string
+	// parseResult parses output of the client pod and fills in results in the status
+	parseResult(cr *v1alpha1.Netperf, output string, status *v1alpha1.NetperfStatus) error
+}
+
+func (n *Netperf) getBackend(cr *v1alpha1.Netperf) backend {
+	switch cr.Spec.Backend {
+	case v1alpha1.NetperfBackendIperf3:
+		return &iperf3Backend{n: n}
+	case v1alpha1.NetperfBackendLatency:
+		return &latencyBackend{n: n}
+	case v1alpha1.NetperfBackendHTTP:
+		return &httpBackend{n: n}
+	case v1alpha1.NetperfBackendDNS:
+		return &dnsBackend{n: n}
+	case v1alpha1.NetperfBackendMTU:
+		return &mtuBackend{n: n}
+	default:
+		return &netperfBackend{n: n}
+	}
+}
+
+// getTestType returns the name of the test run, used to label results
+func (n *Netperf) getTestType(cr *v1alpha1.Netperf) string {
+	return n.getBackend(cr).testType(cr)
+}
+
+func newPolicyPort(protocol v1.Protocol, port int) networkingv1.NetworkPolicyPort {
+	p := intstr.FromInt(port)
+	return networkingv1.NetworkPolicyPort{Protocol: &protocol, Port: &p}
+}
+
+// netperfBackend runs netserver in the server pod and one or more netperf instances in the client pod
+type netperfBackend struct {
+	n *Netperf
+}
+
+func (b *netperfBackend) image(cr *v1alpha1.Netperf) string {
+	return netperfImage
+}
+
+func (b *netperfBackend) serverCommand(cr *v1alpha1.Netperf) []string {
+	return nil
+}
+
+func (b *netperfBackend) clientCommand(cr *v1alpha1.Netperf, serverIP string) []string {
+	return b.n.getNetperfClientCommand(cr, serverIP)
+}
+
+func (b *netperfBackend) ports(cr *v1alpha1.Netperf) []networkingv1.NetworkPolicyPort {
+	ports := []networkingv1.NetworkPolicyPort{newPolicyPort(v1.ProtocolTCP, netperfControlPort)}
+	// each parallel stream uses its own data port
+	for _, stream := range b.n.getNetperfStreams(cr) {
+		ports = append(ports, newPolicyPort(v1.ProtocolTCP, netperfDataPort+stream.id-1))
+	}
+	return ports
+}
+
+func (b *netperfBackend) testType(cr *v1alpha1.Netperf) string {
+	return b.n.getNetperfTestType(cr)
+}
+
+func (b *netperfBackend) parseResult(cr *v1alpha1.Netperf, output string, status *v1alpha1.NetperfStatus) error {
+	var streams []v1alpha1.NetperfStreamResult
+	var throughput float64
+	var err error
+	if b.n.usesParallelCommand(cr) {
+		streams, err = b.n.parseParallelNetperfResult(output, b.n.getNetperfStreams(cr))
+	} else {
+		throughput, err = b.n.parseNetperfResult(output)
+	}
+	if err != nil {
+		return fmt.Errorf("error trying to convert test result to float: %v", err)
+	}
+	b.n.setThroughputResults(cr, status, throughput, streams)
+	if b.n.needsStats(cr) {
+		if status.Stats, err = b.n.parseNetperfStats(output); err != nil {
+			logrus.Errorf("Failed to parse statistics of netperf %s: %v", cr.Name, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,128 @@
+package operator
+
+import (
+	"time"
+
+	"github.com/piontec/netperf-operator/pkg/apis/app/v1alpha1"
+	"github.com/sirupsen/logrus"
+	"k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// shouldCleanup tells if the resources of a test finished with the given status should be
+// deleted right away
+func (n *Netperf) shouldCleanup(cr *v1alpha1.Netperf, status string) bool {
+	switch cr.Spec.CleanupPolicy {
+	case v1alpha1.NetperfCleanupAlways:
+		return true
+	case v1alpha1.NetperfCleanupNever:
+		return false
+	default:
+		return status == v1alpha1.NetperfPhaseDone
+	}
+}
+
+// deleteTestResources deletes the test pods, HTTP service and network policies of the current
+// run. The service is created again by the next run.
+func (n *Netperf) deleteTestResources(cr *v1alpha1.Netperf) error {
+	if err := n.deleteTestPods(cr); err != nil {
+		return err
+	}
+	if n.usesHTTPService(cr) {
+		if err := n.deleteHTTPService(cr); err != nil {
+			return err
+		}
+	}
+	if cr.Status.PolicyEnforced {
+		return n.deleteNetworkPolicies(cr)
+	}
+	return nil
+}
+
+func (n *Netperf) deleteTestPods(cr *v1alpha1.Netperf) error {
+	pods := map[netperfType]string{netperfTypeClient: cr.Status.ClientPod, netperfTypeServer: cr.Status.ServerPod}
+	for npType, name := range pods {
+		if name == "" {
+			continue
+		}
+		namespace := n.getPodNamespace(cr, npType)
+		pod := &v1.Pod{
+			TypeMeta: metav1.TypeMeta{
+				Kind:       "Pod",
+				APIVersion: "v1",
+			},
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      name,
+				Namespace: namespace,
+			},
+		}
+		if err := n.provider.Delete(pod); err != nil && !errors.IsNotFound(err) {
+			logrus.Debugf("Error deleting pod %s/%s: %v", namespace, name, err)
+			return err
+		}
+	}
+	return nil
+}
+
+func (n *Netperf) isPodTTLExpired(cr *v1alpha1.Netperf, now time.Time) bool {
+	if cr.Spec.PodTTLSecondsAfterFinished == nil || cr.Status.CompletionTime == nil {
+		return false
+	}
+	ttl := time.Duration(*cr.Spec.PodTTLSecondsAfterFinished) * time.Second
+	return !now.Before(cr.Status.CompletionTime.Add(ttl))
+}
+
+// cleanupExpiredPods deletes pods kept after the test because of the cleanup policy,
+// once their TTL expires
+func (n *Netperf) cleanupExpiredPods(cr *v1alpha1.Netperf) error {
+	if cr.Status.CleanedUp || !n.isNetperfFinished(cr.Status.Status) || !n.isPodTTLExpired(cr, time.Now()) {
+		return nil
+	}
+	logrus.Debugf("TTL of pods of netperf %s/%s expired, deleting them", cr.Namespace, cr.Name)
+	if err := n.deleteTestResources(cr); err != nil {
+		return err
+	}
+	netperf := cr.DeepCopy()
+	netperf.Status.CleanedUp = true
+	return n.provider.Update(netperf)
+}
+
+func (n *Netperf) getTTLSecondsAfterFinished(cr *v1alpha1.Netperf) *int32 {
+	if cr.Spec.TTLSecondsAfterFinished != nil {
+		return cr.Spec.TTLSecondsAfterFinished
+	}
+	return n.defaultTTL
+}
+
+// isManagedByOwner tells if the Netperf was created by a NetperfMatrix or NetperfSchedule,
+// which keep their own history of the tests and delete them with the owner
+func (n *Netperf) isManagedByOwner(cr *v1alpha1.Netperf) bool {
+	for _, owner := range cr.OwnerReferences {
+		if owner.Kind == "NetperfMatrix" || owner.Kind == "NetperfSchedule" {
+			return true
+		}
+	}
+	return false
+}
+
+// isNetperfExpired tells if the finished Netperf object outlived its TTL. Netperfs managed
+// by an owner never expire.
+func (n *Netperf) isNetperfExpired(cr *v1alpha1.Netperf, now time.Time) bool {
+	ttl := n.getTTLSecondsAfterFinished(cr)
+	if ttl == nil || n.isManagedByOwner(cr) || cr.Status.CompletionTime == nil || !n.isNetperfFinished(cr.Status.Status) {
+		return false
+	}
+	return !now.Before(cr.Status.CompletionTime.Add(time.Duration(*ttl) * time.Second))
+}
+
+// deleteExpiredNetperf deletes the Netperf object, its pods and output are removed by the
+// garbage collector
+func (n *Netperf) deleteExpiredNetperf(cr *v1alpha1.Netperf) error {
+	logrus.Debugf("TTL of netperf %s/%s expired, deleting it", cr.Namespace, cr.Name)
+	if err := n.provider.Delete(cr); err != nil && !errors.IsNotFound(err) {
+		logrus.Errorf("Failed to delete expired netperf %s/%s: %v", cr.Namespace, cr.Name, err)
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,150 @@
+package operator
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+
+	"github.com/piontec/netperf-operator/pkg/apis/app/v1alpha1"
+	"k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+)
+
+const (
+	dnsImage        = latencyImage
+	dnsPort         = 53
+	defaultDNSQPS   = 10
+	defaultDNSName  = "kubernetes.default.svc.cluster.local"
+	defaultDNSCount = 100
+	// dnsScript resolves the names in turns using the libc resolver, like applications do, and
+	// prints the duration of each query and the duration of the whole test in seconds. Queries
+	// are sent one by one, so slow ones lower the rate. Arguments are the rate, the count and
+	// the names.
+	dnsScript = `import socket, sys, time
+qps, count, names = float(sys.argv[1]), int(sys.argv[2]), sys.argv[3:]
+begin = time.time()
+for i in range(count):
+    start = time.time()
+    try:
+        socket.getaddrinfo(names[i % len(names)], None)
+        print("ok=%f" % (time.time() - start))
+    except socket.error:
+        print("failed=%f" % (time.time() - start))
+    sys.stdout.flush()
+    time.sleep(max(0, 1 / qps - (time.time() - start)))
+print("elapsed=%f" % (time.time() - begin))
+`
+)
+
+var (
+	dnsQueryRegexp   = regexp.MustCompile(`(ok|failed)=([0-9.]+)`)
+	dnsElapsedRegexp = regexp.MustCompile(`elapsed=([0-9.]+)`)
+)
+
+// dnsBackend benchmarks DNS resolution in the client pod. The server pod isn't queried, it
+// only keeps the test lifecycle the same as for the other backends.
+type dnsBackend struct {
+	n *Netperf
+}
+
+func (b *dnsBackend) getSpec(cr *v1alpha1.Netperf) v1alpha1.NetperfDNSSpec {
+	spec := v1alpha1.NetperfDNSSpec{}
+	if cr.Spec.DNS != nil {
+		spec = *cr.Spec.DNS
+	}
+	if len(spec.Names) == 0 {
+		spec.Names = []string{defaultDNSName}
+	}
+	if spec.QPS <= 0 {
+		spec.QPS = defaultDNSQPS
+	}
+	if spec.Count <= 0 {
+		spec.Count = defaultDNSCount
+	}
+	return spec
+}
+
+func (b *dnsBackend) image(cr *v1alpha1.Netperf) string {
+	return dnsImage
+}
+
+func (b *dnsBackend) serverCommand(cr *v1alpha1.Netperf) []string {
+	return []string{"sh", "-c", "while true; do sleep 3600; done"}
+}
+
+func (b *dnsBackend) clientCommand(cr *v1alpha1.Netperf, serverIP string) []string {
+	spec := b.getSpec(cr)
+	command := []string{"python3", "-c", dnsScript, strconv.Itoa(spec.QPS), strconv.Itoa(spec.Count)}
+	return append(command, spec.Names...)
+}
+
+func (b *dnsBackend) ports(cr *v1alpha1.Netperf) []networkingv1.NetworkPolicyPort {
+	return nil
+}
+
+func (b *dnsBackend) testType(cr *v1alpha1.Netperf) string {
+	return "DNS"
+}
+
+// parseResult computes the latency distribution of successful queries and the rate actually
+// achieved, which is lower than the target one if the queries take longer than 1/qps
+func (b *dnsBackend) parseResult(cr *v1alpha1.Netperf, output string, status *v1alpha1.NetperfStatus) error {
+	var latencies []float64
+	result := &v1alpha1.NetperfDNSResult{TargetQPS: float64(b.getSpec(cr).QPS)}
+	for _, match := range dnsQueryRegexp.FindAllStringSubmatch(output, -1) {
+		result.Queries++
+		if match[1] == "failed" {
+			result.Failures++
+			continue
+		}
+		value, err := strconv.ParseFloat(match[2], 64)
+		if err != nil {
+			return fmt.Errorf("error parsing query duration %q: %v", match[2], err)
+		}
+		latencies = append(latencies, value*1e6)
+	}
+	if result.Queries == 0 {
+		return fmt.Errorf("No DNS queries found in the output")
+	}
+	result.FailurePercent = float64(result.Failures) / float64(result.Queries) * 100
+	if match := dnsElapsedRegexp.FindStringSubmatch(output); match != nil {
+		elapsed, err := strconv.ParseFloat(match[1], 64)
+		if err != nil {
+			return fmt.Errorf("error parsing test duration %q: %v", match[1], err)
+		}
+		if elapsed > 0 {
+			result.ActualQPS = float64(result.Queries) / elapsed
+		}
+	}
+	if len(latencies) > 0 {
+		distribution := b.n.getLatencyDistribution(latencies)
+		sort.Float64s(latencies)
+		result.MinMicroseconds = distribution.MinMicroseconds
+		result.AvgMicroseconds = distribution.AvgMicroseconds
+		result.P50Microseconds = b.n.getPercentile(latencies, 50)
+		result.P99Microseconds = distribution.P99Microseconds
+		result.MaxMicroseconds = distribution.MaxMicroseconds
+	}
+	status.DNS = result
+	status.Stats = &v1alpha1.NetperfStats{P99LatencyMicroseconds: result.P99Microseconds}
+	return nil
+}
+
+// setDNSConfig points the client pod to the configured DNS server
+func (n *Netperf) setDNSConfig(cr *v1alpha1.Netperf, spec *v1.PodSpec) {
+	if cr.Spec.Backend != v1alpha1.NetperfBackendDNS || cr.Spec.DNS == nil || cr.Spec.DNS.Server == "" {
+		return
+	}
+	spec.DNSPolicy = v1.DNSNone
+	spec.DNSConfig = &v1.PodDNSConfig{Nameservers: []string{cr.Spec.DNS.Server}}
+}
+
+// needsDNSEgress tells if the client resolves names, so its network policy has to allow DNS
+func (n *Netperf) needsDNSEgress(cr *v1alpha1.Netperf) bool {
+	return cr.Spec.Backend == v1alpha1.NetperfBackendDNS || n.usesHTTPService(cr)
+}
+
+func (n *Netperf) getDNSPolicyPorts() []networkingv1.NetworkPolicyPort {
+	return []networkingv1.NetworkPolicyPort{newPolicyPort(v1.ProtocolUDP, dnsPort), newPolicyPort(v1.ProtocolTCP, dnsPort)}
+}
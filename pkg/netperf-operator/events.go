@@ -0,0 +1,65 @@
+package operator
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/piontec/netperf-operator/pkg/apis/app/v1alpha1"
+	"github.com/sirupsen/logrus"
+	"k8s.io/api/core/v1"
+)
+
+// reasons of Events recorded on Netperf and NetperfSchedule objects
+const (
+	eventServerPodCreated = "ServerPodCreated"
+	eventClientPodCreated = "ClientPodCreated"
+	eventTestCompleted    = "TestCompleted"
+	eventParseFailed      = "ParseFailed"
+	eventPodFailed        = "PodFailed"
+	eventTimeout          = "Timeout"
+	eventInvalidSpec      = "InvalidSpec"
+	eventMissedSchedule   = "MissedSchedule"
+)
+
+// getResultSummary describes the main result of the finished test: throughput, the path MTU
+// or the 99th percentile latency, depending on the backend
+func (n *Netperf) getResultSummary(cr *v1alpha1.Netperf) string {
+	switch {
+	case cr.Spec.MeasuresThroughput():
+		return fmt.Sprintf("throughput: %.2f Mbit/s", cr.Status.SpeedBitsPerSec)
+	case cr.Status.MTU != nil:
+		return fmt.Sprintf("path MTU: %d", cr.Status.MTU.PathMTU)
+	default:
+		return fmt.Sprintf("p99 latency: %.2fus", n.getP99Latency(cr))
+	}
+}
+
+func (n *Netperf) isTimedOut(cr *v1alpha1.Netperf, now time.Time) bool {
+	if cr.Spec.TimeoutSeconds < 1 || cr.Status.StartTime == nil {
+		return false
+	}
+	return !now.Before(cr.Status.StartTime.Add(time.Duration(cr.Spec.TimeoutSeconds) * time.Second))
+}
+
+// checkTimeout fails the running test if it didn't finish within its timeout
+func (n *Netperf) checkTimeout(cr *v1alpha1.Netperf) (bool, error) {
+	if !n.isTimedOut(cr, time.Now()) {
+		return false, nil
+	}
+	logrus.Debugf("Netperf %s/%s timed out in state %s", cr.Namespace, cr.Name, cr.Status.Status)
+	n.recorder.Eventf(cr, v1.EventTypeWarning, eventTimeout, "Test didn't finish within %ds", cr.Spec.TimeoutSeconds)
+	cr = n.saveFailedTestOutput(cr)
+	return true, n.updateNetperfStatus(cr, v1alpha1.NetperfPhaseError)
+}
+
+// handleFailedPod fails the test if one of its pods failed
+func (n *Netperf) handleFailedPod(cr *v1alpha1.Netperf, pod *v1.Pod) error {
+	if n.isNetperfFinished(cr.Status.Status) {
+		return nil
+	}
+	logrus.Debugf("Pod %s/%s of netperf %s failed: %s", pod.Namespace, pod.Name, cr.Name, pod.Status.Reason)
+	n.recorder.Eventf(cr, v1.EventTypeWarning, eventPodFailed, "Pod %s failed: %s %s", pod.Name,
+		pod.Status.Reason, pod.Status.Message)
+	cr = n.saveFailedTestOutput(cr)
+	return n.updateNetperfStatus(cr, v1alpha1.NetperfPhaseError)
+}
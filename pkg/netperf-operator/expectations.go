@@ -0,0 +1,182 @@
+package operator
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/piontec/netperf-operator/pkg/apis/app/v1alpha1"
+	"k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// netperf omni output selectors, printed as "KEY=VALUE" lines with the -k option
+const (
+	selectorThroughput      = "THROUGHPUT"
+	selectorP99Latency      = "P99_LATENCY"
+	selectorLocalRetrans    = "LOCAL_TRANSPORT_RETRANS"
+	selectorRemoteRetrans   = "REMOTE_TRANSPORT_RETRANS"
+	selectorLocalCPUUtil    = "LOCAL_CPU_UTIL"
+	selectorRemoteCPUUtil   = "REMOTE_CPU_UTIL"
+	eventExpectationsNotMet = "ExpectationsNotMet"
+)
+
+// needsStats tells if the expectations check more than throughput, so netperf has to
+// collect additional statistics
+func (n *Netperf) needsStats(cr *v1alpha1.Netperf) bool {
+	return n.expectsStats(cr.Spec.Expectations)
+}
+
+func (n *Netperf) expectsStats(e *v1alpha1.NetperfExpectations) bool {
+	return e != nil && (e.MaxP99LatencyMicroseconds > 0 || e.MaxRetransmits != nil || e.MaxCPUPercent > 0)
+}
+
+// parseKeyvalThroughput returns the throughput from output of netperf run with the -k option
+func (n *Netperf) parseKeyvalThroughput(output string) (float64, bool) {
+	values := n.parseKeyvalOutput(output)[selectorThroughput]
+	if len(values) == 0 {
+		return 0, false
+	}
+	return values[0], true
+}
+
+// getNetperfOptions returns options appended to each netperf command: global options
+// enabling the statistics, then test specific options after "--"
+func (n *Netperf) getNetperfOptions(cr *v1alpha1.Netperf, dataPort int) []string {
+	var global, test []string
+	if n.usesNetworkPolicy(cr) {
+		// pin the data connection port, so it can be allowed by the network policies
+		test = append(test, "-P", fmt.Sprintf(",%d", dataPort))
+	}
+	if n.needsStats(cr) {
+		global = append(global, "-j", "-c", "-C")
+		test = append(test, "-k", strings.Join([]string{selectorThroughput, selectorP99Latency,
+			selectorLocalRetrans, selectorRemoteRetrans, selectorLocalCPUUtil, selectorRemoteCPUUtil}, ","))
+	}
+	if len(test) == 0 {
+		return global
+	}
+	return append(append(global, "--"), test...)
+}
+
+// parseKeyvalOutput returns all the numeric "KEY=VALUE" entries found in the output. A key
+// is repeated once for each stream.
+func (n *Netperf) parseKeyvalOutput(output string) map[string][]float64 {
+	values := map[string][]float64{}
+	for _, field := range strings.Fields(output) {
+		parts := strings.SplitN(field, "=", 2)
+		if len(parts) != 2 {
+			continue
+		}
+		if value, err := strconv.ParseFloat(parts[1], 64); err == nil {
+			values[parts[0]] = append(values[parts[0]], value)
+		}
+	}
+	return values
+}
+
+// parseNetperfStats aggregates statistics of all the streams. Negative values mean netperf
+// couldn't measure the statistic and are ignored.
+func (n *Netperf) parseNetperfStats(output string) (*v1alpha1.NetperfStats, error) {
+	values := n.parseKeyvalOutput(output)
+	if len(values[selectorThroughput]) == 0 {
+		return nil, fmt.Errorf("No statistics found in netperf output")
+	}
+	max := func(key string) float64 {
+		result := 0.0
+		for _, v := range values[key] {
+			result = math.Max(result, v)
+		}
+		return result
+	}
+	stats := &v1alpha1.NetperfStats{
+		P99LatencyMicroseconds: max(selectorP99Latency),
+		ClientCPUPercent:       max(selectorLocalCPUUtil),
+		ServerCPUPercent:       max(selectorRemoteCPUUtil),
+	}
+	for _, key := range []string{selectorLocalRetrans, selectorRemoteRetrans} {
+		for _, v := range values[key] {
+			if v > 0 {
+				stats.Retransmits += int64(v)
+			}
+		}
+	}
+	return stats, nil
+}
+
+// checkExpectations returns descriptions of all the expectations the results don't meet
+func (n *Netperf) checkExpectations(e *v1alpha1.NetperfExpectations, status *v1alpha1.NetperfStatus) []string {
+	var failures []string
+	if e.MinThroughputBitsPerSec > 0 && status.SpeedBitsPerSec < e.MinThroughputBitsPerSec {
+		failures = append(failures, fmt.Sprintf("throughput %.2f is below %.2f",
+			status.SpeedBitsPerSec, e.MinThroughputBitsPerSec))
+	}
+	if !n.expectsStats(e) {
+		return failures
+	}
+	stats := status.Stats
+	if stats == nil {
+		return append(failures, "statistics weren't collected")
+	}
+	if e.MaxP99LatencyMicroseconds > 0 && stats.P99LatencyMicroseconds > e.MaxP99LatencyMicroseconds {
+		failures = append(failures, fmt.Sprintf("p99 latency %.2fus is above %.2fus",
+			stats.P99LatencyMicroseconds, e.MaxP99LatencyMicroseconds))
+	}
+	if e.MaxRetransmits != nil && stats.Retransmits > *e.MaxRetransmits {
+		failures = append(failures, fmt.Sprintf("%d retransmits is above %d", stats.Retransmits, *e.MaxRetransmits))
+	}
+	if e.MaxCPUPercent > 0 && math.Max(stats.ClientCPUPercent, stats.ServerCPUPercent) > e.MaxCPUPercent {
+		failures = append(failures, fmt.Sprintf("CPU utilization %.2f%%/%.2f%% (client/server) is above %.2f%%",
+			stats.ClientCPUPercent, stats.ServerCPUPercent, e.MaxCPUPercent))
+	}
+	return failures
+}
+
+// setCondition adds or updates the condition, keeping its transition time if the status didn't change
+func (n *Netperf) setCondition(status *v1alpha1.NetperfStatus, condition v1alpha1.NetperfCondition) {
+	condition.LastTransitionTime = metav1.Now()
+	for i := range status.Conditions {
+		if status.Conditions[i].Type != condition.Type {
+			continue
+		}
+		if status.Conditions[i].Status == condition.Status {
+			condition.LastTransitionTime = status.Conditions[i].LastTransitionTime
+		}
+		status.Conditions[i] = condition
+		return
+	}
+	status.Conditions = append(status.Conditions, condition)
+}
+
+// setVerdict checks the results of a finished test against its expectations and records
+// the verdict in the status. Failed tests don't pass.
+func (n *Netperf) setVerdict(cr *v1alpha1.Netperf, status *v1alpha1.NetperfStatus) {
+	if cr.Spec.Expectations == nil {
+		return
+	}
+	condition := v1alpha1.NetperfCondition{
+		Type:    v1alpha1.NetperfConditionPassed,
+		Status:  string(v1.ConditionTrue),
+		Reason:  "ExpectationsMet",
+		Message: "All expectations are met",
+	}
+	if status.Status == v1alpha1.NetperfPhaseError {
+		condition.Status = string(v1.ConditionFalse)
+		condition.Reason = "TestFailed"
+		condition.Message = "Test finished with error"
+	} else if failures := n.checkExpectations(cr.Spec.Expectations, status); len(failures) > 0 {
+		condition.Status = string(v1.ConditionFalse)
+		condition.Reason = eventExpectationsNotMet
+		condition.Message = strings.Join(failures, ", ")
+	}
+
+	status.Verdict = v1alpha1.NetperfVerdictPass
+	if condition.Status != string(v1.ConditionTrue) {
+		status.Verdict = v1alpha1.NetperfVerdictFail
+	}
+	n.setCondition(status, condition)
+	if condition.Reason == eventExpectationsNotMet {
+		n.recorder.Eventf(cr, v1.EventTypeWarning, eventExpectationsNotMet, "Test failed: %s", condition.Message)
+	}
+}
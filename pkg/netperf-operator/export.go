@@ -0,0 +1,70 @@
+package operator
+
+import (
+	"time"
+
+	"github.com/piontec/netperf-operator/pkg/apis/app/v1alpha1"
+	"github.com/piontec/netperf-operator/pkg/export"
+	"github.com/sirupsen/logrus"
+)
+
+func (n *Netperf) newExportResult(cr *v1alpha1.Netperf) export.Result {
+	result := export.Result{
+		Namespace:                cr.Namespace,
+		Name:                     cr.Name,
+		Run:                      cr.Status.Run,
+		Time:                     time.Now(),
+		ServerNode:               cr.Status.ServerNode,
+		ClientNode:               cr.Status.ClientNode,
+		TestType:                 n.getTestType(cr),
+		Direction:                n.getDirection(cr),
+		ParallelStreams:          n.getStreamCount(cr),
+		SpeedBitsPerSec:          n.getThroughput(cr),
+		ClientToServerBitsPerSec: cr.Status.ClientToServerBitsPerSec,
+		ServerToClientBitsPerSec: cr.Status.ServerToClientBitsPerSec,
+		P99LatencyMicroseconds:   n.getP99Latency(cr),
+		Verdict:                  cr.Status.Verdict,
+	}
+	if cr.Status.CompletionTime != nil {
+		result.Time = cr.Status.CompletionTime.Time
+	}
+	if cr.Status.Stats != nil {
+		result.Retransmits = cr.Status.Stats.Retransmits
+	}
+	return result
+}
+
+// getThroughput returns the throughput of the last run, or 0 if the backend doesn't measure it
+func (n *Netperf) getThroughput(cr *v1alpha1.Netperf) float64 {
+	if !cr.Spec.MeasuresThroughput() {
+		return 0
+	}
+	return cr.Status.SpeedBitsPerSec
+}
+
+// getP99Latency returns the 99th percentile latency of the last run, or 0 if it wasn't measured
+func (n *Netperf) getP99Latency(cr *v1alpha1.Netperf) float64 {
+	if cr.Status.Stats == nil {
+		return 0
+	}
+	return cr.Status.Stats.P99LatencyMicroseconds
+}
+
+// exportResult sends the result of the successfully finished test to all the exporters
+// in background. Failed exports are only logged.
+func (n *Netperf) exportResult(cr *v1alpha1.Netperf) {
+	if len(n.exporters) == 0 {
+		return
+	}
+	result := n.newExportResult(cr)
+	go func() {
+		n.exportLock.Lock()
+		defer n.exportLock.Unlock()
+		for _, exporter := range n.exporters {
+			if err := exporter.Export(result); err != nil {
+				logrus.Errorf("Failed to export result of netperf %s/%s to %s: %v", result.Namespace,
+					result.Name, exporter.Name(), err)
+			}
+		}
+	}()
+}
@@ -0,0 +1,96 @@
+package operator
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/piontec/netperf-operator/pkg/apis/app/v1alpha1"
+	"github.com/sirupsen/logrus"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	// rerunAnnotation starts a new run of a finished test each time its value is changed
+	rerunAnnotation     = "app.example.com/rerun"
+	defaultHistoryLimit = 10
+)
+
+func (n *Netperf) getHistoryLimit(cr *v1alpha1.Netperf) int {
+	if cr.Spec.HistoryLimit < 1 {
+		return defaultHistoryLimit
+	}
+	return cr.Spec.HistoryLimit
+}
+
+func (n *Netperf) needsRerun(cr *v1alpha1.Netperf) bool {
+	value, found := cr.Annotations[rerunAnnotation]
+	return found && value != cr.Status.Rerun && n.isNetperfFinished(cr.Status.Status)
+}
+
+// startRerun resets the status of a finished test, so that it's started again from the beginning.
+// Pods of the new run get new names, so they don't collide with pods of the previous run,
+// which might still be terminating.
+func (n *Netperf) startRerun(cr *v1alpha1.Netperf) error {
+	logrus.Debugf("Re-run of netperf %s/%s requested", cr.Namespace, cr.Name)
+	if !cr.Status.CleanedUp {
+		if err := n.deleteTestResources(cr); err != nil {
+			logrus.Errorf("Failed to delete resources of the previous run of netperf %s: %v", cr.Name, err)
+			return err
+		}
+	}
+
+	netperf := cr.DeepCopy()
+	netperf.Status = v1alpha1.NetperfStatus{
+		Status:     v1alpha1.NetperfPhaseInitial,
+		Run:        cr.Status.Run + 1,
+		Rerun:      cr.Annotations[rerunAnnotation],
+		History:    netperf.Status.History,
+		Conditions: netperf.Status.Conditions,
+	}
+	return n.provider.Update(netperf)
+}
+
+// finishRun sets the final status of the current run and adds its result to the history
+func (n *Netperf) finishRun(cr *v1alpha1.Netperf, status string) {
+	now := metav1.Now()
+	cr.Status.Status = status
+	cr.Status.CompletionTime = &now
+	result := v1alpha1.NetperfRunResult{
+		Run:                      cr.Status.Run,
+		Status:                   status,
+		CompletionTime:           now,
+		ServerNode:               cr.Status.ServerNode,
+		ClientNode:               cr.Status.ClientNode,
+		TestType:                 n.getTestType(cr),
+		ParallelStreams:          n.getStreamCount(cr),
+		SpeedBitsPerSec:          cr.Status.SpeedBitsPerSec,
+		ClientToServerBitsPerSec: cr.Status.ClientToServerBitsPerSec,
+		ServerToClientBitsPerSec: cr.Status.ServerToClientBitsPerSec,
+		Verdict:                  cr.Status.Verdict,
+	}
+	if cr.Status.StartTime != nil {
+		result.StartTime = *cr.Status.StartTime
+	}
+	if cr.Status.Stats != nil {
+		result.P99LatencyMicroseconds = cr.Status.Stats.P99LatencyMicroseconds
+	}
+	cr.Status.History = append(cr.Status.History, result)
+	if limit := n.getHistoryLimit(cr); len(cr.Status.History) > limit {
+		cr.Status.History = cr.Status.History[len(cr.Status.History)-limit:]
+	}
+}
+
+// getRunDuration returns time since the start of the current run
+func (n *Netperf) getRunDuration(cr *v1alpha1.Netperf) time.Duration {
+	if cr.Status.StartTime != nil {
+		return time.Since(cr.Status.StartTime.Time)
+	}
+	return time.Since(cr.CreationTimestamp.Time)
+}
+
+func (n *Netperf) getRunSuffix(cr *v1alpha1.Netperf) string {
+	if cr.Status.Run == 0 {
+		return ""
+	}
+	return fmt.Sprintf("-%d", cr.Status.Run)
+}
@@ -0,0 +1,195 @@
+package operator
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/piontec/netperf-operator/pkg/apis/app/v1alpha1"
+	"github.com/sirupsen/logrus"
+	"k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+)
+
+const (
+	httpImage                  = "fortio/fortio"
+	httpPort                   = 8080
+	defaultHTTPQPS             = 100
+	defaultHTTPDurationSeconds = 10
+)
+
+// fortioOutput is the part of fortio load -json output used by the operator
+type fortioOutput struct {
+	ActualQPS         float64 `json:"ActualQPS"`
+	DurationHistogram struct {
+		Count       int64   `json:"Count"`
+		Max         float64 `json:"Max"`
+		Avg         float64 `json:"Avg"`
+		Percentiles []struct {
+			Percentile float64 `json:"Percentile"`
+			Value      float64 `json:"Value"`
+		} `json:"Percentiles"`
+	} `json:"DurationHistogram"`
+	RetCodes map[string]int64 `json:"RetCodes"`
+}
+
+// httpBackend runs the fortio echo server in the server pod and the fortio load generator
+// in the client pod
+type httpBackend struct {
+	n *Netperf
+}
+
+func (b *httpBackend) getSpec(cr *v1alpha1.Netperf) v1alpha1.NetperfHTTPSpec {
+	spec := v1alpha1.NetperfHTTPSpec{}
+	if cr.Spec.HTTP != nil {
+		spec = *cr.Spec.HTTP
+	}
+	if spec.QPS <= 0 {
+		spec.QPS = defaultHTTPQPS
+	}
+	if spec.Connections <= 0 {
+		spec.Connections = 1
+	}
+	if spec.DurationSeconds <= 0 {
+		spec.DurationSeconds = defaultHTTPDurationSeconds
+	}
+	if !strings.HasPrefix(spec.Path, "/") {
+		spec.Path = "/" + spec.Path
+	}
+	return spec
+}
+
+func (b *httpBackend) image(cr *v1alpha1.Netperf) string {
+	return httpImage
+}
+
+func (b *httpBackend) serverCommand(cr *v1alpha1.Netperf) []string {
+	return []string{"fortio", "server", "-http-port", strconv.Itoa(httpPort)}
+}
+
+func (b *httpBackend) clientCommand(cr *v1alpha1.Netperf, serverIP string) []string {
+	spec := b.getSpec(cr)
+	host := fmt.Sprintf("%s:%d", serverIP, httpPort)
+	if spec.ViaService {
+		host = fmt.Sprintf("%s.%s.svc:%d", b.n.getHTTPServiceName(cr),
+			b.n.getPodNamespace(cr, netperfTypeServer), httpPort)
+	}
+	return []string{"fortio", "load", "-qps", strconv.Itoa(spec.QPS), "-c", strconv.Itoa(spec.Connections),
+		"-t", fmt.Sprintf("%ds", spec.DurationSeconds), "-p", "50,90,99", "-json", "-",
+		"http://" + host + spec.Path}
+}
+
+func (b *httpBackend) ports(cr *v1alpha1.Netperf) []networkingv1.NetworkPolicyPort {
+	return []networkingv1.NetworkPolicyPort{newPolicyPort(v1.ProtocolTCP, httpPort)}
+}
+
+func (b *httpBackend) testType(cr *v1alpha1.Netperf) string {
+	if b.getSpec(cr).ViaService {
+		return "HTTP_SERVICE"
+	}
+	return "HTTP"
+}
+
+// parseResult reads the JSON printed by fortio after its log messages, if there are any.
+// Responses with other status than 200 and failed connections are errors.
+func (b *httpBackend) parseResult(cr *v1alpha1.Netperf, output string, status *v1alpha1.NetperfStatus) error {
+	start := 0
+	if !strings.HasPrefix(output, "{") {
+		if start = strings.Index(output, "\n{") + 1; start == 0 {
+			return fmt.Errorf("No fortio results found in the output")
+		}
+	}
+	var result fortioOutput
+	if err := json.Unmarshal([]byte(output[start:]), &result); err != nil {
+		return fmt.Errorf("error parsing fortio output: %v", err)
+	}
+	requests := result.DurationHistogram.Count
+	if requests == 0 {
+		return fmt.Errorf("No requests were sent by fortio")
+	}
+
+	http := &v1alpha1.NetperfHTTPResult{
+		TargetQPS:       float64(b.getSpec(cr).QPS),
+		ActualQPS:       result.ActualQPS,
+		Requests:        requests,
+		Errors:          requests - result.RetCodes["200"],
+		AvgMicroseconds: result.DurationHistogram.Avg * 1e6,
+		MaxMicroseconds: result.DurationHistogram.Max * 1e6,
+	}
+	http.ErrorPercent = float64(http.Errors) / float64(requests) * 100
+	for _, p := range result.DurationHistogram.Percentiles {
+		switch p.Percentile {
+		case 50:
+			http.P50Microseconds = p.Value * 1e6
+		case 90:
+			http.P90Microseconds = p.Value * 1e6
+		case 99:
+			http.P99Microseconds = p.Value * 1e6
+		}
+	}
+	status.HTTP = http
+	status.Stats = &v1alpha1.NetperfStats{P99LatencyMicroseconds: http.P99Microseconds}
+	return nil
+}
+
+func (n *Netperf) usesHTTPService(cr *v1alpha1.Netperf) bool {
+	return cr.Spec.Backend == v1alpha1.NetperfBackendHTTP && cr.Spec.HTTP != nil && cr.Spec.HTTP.ViaService
+}
+
+func (n *Netperf) getHTTPServiceName(cr *v1alpha1.Netperf) string {
+	return "netperf-http-" + n.getNetperfSuffix(cr)
+}
+
+// newHTTPService selects the server pod of the current run, it's shared by all the runs
+func (n *Netperf) newHTTPService(cr *v1alpha1.Netperf) *v1.Service {
+	return &v1.Service{
+		TypeMeta: metav1.TypeMeta{
+			Kind:       "Service",
+			APIVersion: "v1",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:            n.getHTTPServiceName(cr),
+			Namespace:       n.getPodNamespace(cr, netperfTypeServer),
+			OwnerReferences: n.getOwnerReferences(cr, n.getPodNamespace(cr, netperfTypeServer)),
+			Labels:          map[string]string{"app": "netperf-operator"},
+		},
+		Spec: v1.ServiceSpec{
+			Selector: map[string]string{
+				"netperf-type": fmt.Sprint(netperfTypeServer),
+				"netperf-id":   n.getNetperfSuffix(cr),
+			},
+			Ports: []v1.ServicePort{
+				{
+					Name:       "http",
+					Protocol:   v1.ProtocolTCP,
+					Port:       httpPort,
+					TargetPort: intstr.FromInt(httpPort),
+				},
+			},
+		},
+	}
+}
+
+func (n *Netperf) createHTTPService(cr *v1alpha1.Netperf) error {
+	service := n.newHTTPService(cr)
+	err := n.provider.Create(service)
+	if err != nil && !errors.IsAlreadyExists(err) {
+		logrus.Errorf("Failed to create service %s/%s: %v", service.Namespace, service.Name, err)
+		return err
+	}
+	logrus.Debugf("Service %s/%s is ready for netperf: %s", service.Namespace, service.Name, cr.Name)
+	return nil
+}
+
+func (n *Netperf) deleteHTTPService(cr *v1alpha1.Netperf) error {
+	service := n.newHTTPService(cr)
+	if err := n.provider.Delete(service); err != nil && !errors.IsNotFound(err) {
+		logrus.Debugf("Error deleting service %s/%s: %v", service.Namespace, service.Name, err)
+		return err
+	}
+	return nil
+}
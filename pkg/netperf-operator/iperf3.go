@@ -0,0 +1,241 @@
+package operator
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/piontec/netperf-operator/pkg/apis/app/v1alpha1"
+	"k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+)
+
+const (
+	iperf3Image = "networkstatic/iperf3"
+	iperf3Port  = 5201
+	// iperf3 reports bits per second, results are stored in 10^6 bits per second like netperf's
+	iperf3BitsPerUnit = 1e6
+	// maxIperf3Intervals limits the size of the interval series stored in the status
+	maxIperf3Intervals = 60
+)
+
+// iperf3Output is the part of iperf3 -J output used by the operator
+type iperf3Output struct {
+	Intervals []iperf3Interval `json:"intervals"`
+	End       struct {
+		Streams []struct {
+			Sender   *iperf3Summary `json:"sender"`
+			Receiver *iperf3Summary `json:"receiver"`
+			UDP      *iperf3Summary `json:"udp"`
+		} `json:"streams"`
+		SumSent             *iperf3Summary `json:"sum_sent"`
+		SumSentBidirReverse *iperf3Summary `json:"sum_sent_bidir_reverse"`
+		Sum                 *iperf3Summary `json:"sum"`
+		CPU                 struct {
+			HostTotal   float64 `json:"host_total"`
+			RemoteTotal float64 `json:"remote_total"`
+		} `json:"cpu_utilization_percent"`
+	} `json:"end"`
+	Error string `json:"error"`
+}
+
+type iperf3Summary struct {
+	BitsPerSecond float64 `json:"bits_per_second"`
+	Retransmits   int64   `json:"retransmits"`
+	// Sender is true if the client sent the data, it's missing in output of old iperf3 versions
+	Sender      *bool   `json:"sender"`
+	JitterMs    float64 `json:"jitter_ms"`
+	LostPackets int64   `json:"lost_packets"`
+	Packets     int64   `json:"packets"`
+	LostPercent float64 `json:"lost_percent"`
+}
+
+type iperf3Interval struct {
+	Sum             *iperf3IntervalSum `json:"sum"`
+	SumBidirReverse *iperf3IntervalSum `json:"sum_bidir_reverse"`
+}
+
+// iperf3IntervalSum is the throughput of all the streams in one reporting interval
+type iperf3IntervalSum struct {
+	Start         float64 `json:"start"`
+	End           float64 `json:"end"`
+	BitsPerSecond float64 `json:"bits_per_second"`
+	// Omitted is true for the intervals at the beginning of the test skipped with the -O option
+	Omitted bool `json:"omitted"`
+}
+
+// iperf3Backend runs iperf3 in the server mode in the server pod and a single iperf3 client,
+// which handles all the parallel streams, in the client pod
+type iperf3Backend struct {
+	n *Netperf
+}
+
+func (b *iperf3Backend) getSpec(cr *v1alpha1.Netperf) v1alpha1.NetperfIperf3Spec {
+	if cr.Spec.Iperf3 == nil {
+		return v1alpha1.NetperfIperf3Spec{}
+	}
+	return *cr.Spec.Iperf3
+}
+
+func (b *iperf3Backend) image(cr *v1alpha1.Netperf) string {
+	return iperf3Image
+}
+
+func (b *iperf3Backend) serverCommand(cr *v1alpha1.Netperf) []string {
+	return []string{"iperf3", "-s", "-p", strconv.Itoa(iperf3Port)}
+}
+
+func (b *iperf3Backend) clientCommand(cr *v1alpha1.Netperf, serverIP string) []string {
+	spec := b.getSpec(cr)
+	command := []string{"iperf3", "-c", serverIP, "-p", strconv.Itoa(iperf3Port), "-J"}
+	if spec.DurationSeconds > 0 {
+		command = append(command, "-t", strconv.Itoa(spec.DurationSeconds))
+	}
+	if streams := b.n.getStreamCount(cr); streams > 1 {
+		command = append(command, "-P", strconv.Itoa(streams))
+	}
+	switch b.n.getDirection(cr) {
+	case v1alpha1.NetperfDirectionServerToClient:
+		command = append(command, "-R")
+	case v1alpha1.NetperfDirectionBidirectional:
+		command = append(command, "--bidir")
+	}
+	if spec.UDP {
+		command = append(command, "-u")
+	}
+	if spec.Bandwidth != "" {
+		command = append(command, "-b", spec.Bandwidth)
+	}
+	return command
+}
+
+func (b *iperf3Backend) ports(cr *v1alpha1.Netperf) []networkingv1.NetworkPolicyPort {
+	ports := []networkingv1.NetworkPolicyPort{newPolicyPort(v1.ProtocolTCP, iperf3Port)}
+	if b.getSpec(cr).UDP {
+		ports = append(ports, newPolicyPort(v1.ProtocolUDP, iperf3Port))
+	}
+	return ports
+}
+
+func (b *iperf3Backend) testType(cr *v1alpha1.Netperf) string {
+	protocol := "TCP"
+	if b.getSpec(cr).UDP {
+		protocol = "UDP"
+	}
+	switch b.n.getDirection(cr) {
+	case v1alpha1.NetperfDirectionServerToClient:
+		return "IPERF3_" + protocol + "_REVERSE"
+	case v1alpha1.NetperfDirectionBidirectional:
+		return "IPERF3_" + protocol + "_BIDIR"
+	}
+	return "IPERF3_" + protocol
+}
+
+// getStreamDirection uses the sender flag of the stream if iperf3 reports it
+func (b *iperf3Backend) getStreamDirection(cr *v1alpha1.Netperf, summary *iperf3Summary) string {
+	if summary.Sender == nil {
+		direction := b.n.getDirection(cr)
+		if direction == v1alpha1.NetperfDirectionBidirectional {
+			return ""
+		}
+		return direction
+	}
+	if *summary.Sender {
+		return v1alpha1.NetperfDirectionClientToServer
+	}
+	return v1alpha1.NetperfDirectionServerToClient
+}
+
+// parseResult reads the JSON output. Throughput of TCP streams is measured by the receiver,
+// throughput of UDP streams is their bitrate without the lost datagrams.
+func (b *iperf3Backend) parseResult(cr *v1alpha1.Netperf, output string, status *v1alpha1.NetperfStatus) error {
+	var result iperf3Output
+	// the JSON object may be preceded by messages printed by the image
+	if start := strings.Index(output, "{"); start > 0 {
+		output = output[start:]
+	}
+	if err := json.Unmarshal([]byte(output), &result); err != nil {
+		return fmt.Errorf("error parsing iperf3 output: %v", err)
+	}
+	if result.Error != "" {
+		return fmt.Errorf("iperf3 failed: %s", result.Error)
+	}
+	if len(result.End.Streams) == 0 {
+		return fmt.Errorf("No streams found in iperf3 output")
+	}
+
+	var streams []v1alpha1.NetperfStreamResult
+	for i, s := range result.End.Streams {
+		stream := v1alpha1.NetperfStreamResult{Stream: i + 1}
+		switch {
+		case s.UDP != nil:
+			stream.Direction = b.getStreamDirection(cr, s.UDP)
+			stream.SpeedBitsPerSec = s.UDP.BitsPerSecond * (100 - s.UDP.LostPercent) / 100 / iperf3BitsPerUnit
+		case s.Receiver != nil && s.Sender != nil:
+			stream.Direction = b.getStreamDirection(cr, s.Sender)
+			stream.SpeedBitsPerSec = s.Receiver.BitsPerSecond / iperf3BitsPerUnit
+		default:
+			return fmt.Errorf("No results of stream %d found in iperf3 output", i+1)
+		}
+		streams = append(streams, stream)
+	}
+	if len(streams) == 1 {
+		b.n.setThroughputResults(cr, status, streams[0].SpeedBitsPerSec, nil)
+	} else {
+		b.n.setThroughputResults(cr, status, 0, streams)
+	}
+
+	stats := &v1alpha1.NetperfStats{
+		ClientCPUPercent: result.End.CPU.HostTotal,
+		ServerCPUPercent: result.End.CPU.RemoteTotal,
+	}
+	for _, sum := range []*iperf3Summary{result.End.SumSent, result.End.SumSentBidirReverse} {
+		if sum != nil {
+			stats.Retransmits += sum.Retransmits
+		}
+	}
+	status.Stats = stats
+	status.Intervals = b.getIntervals(&result)
+	if b.getSpec(cr).UDP && result.End.Sum != nil {
+		status.UDP = &v1alpha1.NetperfUDPResult{
+			JitterMilliseconds: result.End.Sum.JitterMs,
+			LostPackets:        result.End.Sum.LostPackets,
+			Packets:            result.End.Sum.Packets,
+			LostPercent:        result.End.Sum.LostPercent,
+		}
+	}
+	return nil
+}
+
+// getIntervals sums the throughput of both directions of each reporting interval. Only the first
+// maxIperf3Intervals are kept in the series, but min and max are computed from all of them.
+func (b *iperf3Backend) getIntervals(result *iperf3Output) *v1alpha1.NetperfIntervalsResult {
+	var intervals *v1alpha1.NetperfIntervalsResult
+	for _, i := range result.Intervals {
+		if i.Sum == nil || i.Sum.Omitted {
+			continue
+		}
+		interval := v1alpha1.NetperfInterval{
+			StartSeconds:    i.Sum.Start,
+			EndSeconds:      i.Sum.End,
+			SpeedBitsPerSec: i.Sum.BitsPerSecond / iperf3BitsPerUnit,
+		}
+		if i.SumBidirReverse != nil {
+			interval.SpeedBitsPerSec += i.SumBidirReverse.BitsPerSecond / iperf3BitsPerUnit
+		}
+		if intervals == nil {
+			intervals = &v1alpha1.NetperfIntervalsResult{
+				MinBitsPerSec: interval.SpeedBitsPerSec,
+				MaxBitsPerSec: interval.SpeedBitsPerSec,
+			}
+		}
+		intervals.MinBitsPerSec = math.Min(intervals.MinBitsPerSec, interval.SpeedBitsPerSec)
+		intervals.MaxBitsPerSec = math.Max(intervals.MaxBitsPerSec, interval.SpeedBitsPerSec)
+		if len(intervals.Series) < maxIperf3Intervals {
+			intervals.Series = append(intervals.Series, interval)
+		}
+	}
+	return intervals
+}
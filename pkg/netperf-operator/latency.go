@@ -0,0 +1,233 @@
+package operator
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+	"sort"
+	"strconv"
+
+	"github.com/piontec/netperf-operator/pkg/apis/app/v1alpha1"
+	"k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+)
+
+const (
+	latencyImage                       = "nicolaka/netshoot"
+	latencyPort                        = 11111
+	defaultLatencyCount                = 100
+	defaultLatencyIntervalMilliseconds = 200
+	defaultSockperfDurationSeconds     = 10
+)
+
+var (
+	// "64 bytes from 10.0.0.2: icmp_seq=1 ttl=64 time=0.089 ms"
+	pingTimeRegexp = regexp.MustCompile(`time=([0-9.]+) ms`)
+	// "connect=0.000123", printed in seconds by curl for each TCP connect probe
+	tcpConnectRegexp = regexp.MustCompile(`connect=([0-9.]+)`)
+	// "sockperf: ---> percentile 99.000 =   28.193"
+	sockperfPercentileRegexp = regexp.MustCompile(`---> percentile 99\.000 =\s*([0-9.]+)`)
+	sockperfMinRegexp        = regexp.MustCompile(`---> <MIN> observation =\s*([0-9.]+)`)
+	sockperfMaxRegexp        = regexp.MustCompile(`---> <MAX> observation =\s*([0-9.]+)`)
+	// "sockperf: ====> avg-rtt=44.678 (std-dev=4.376)"
+	sockperfAvgRegexp      = regexp.MustCompile(`avg-(?:rtt|lat)=\s*([0-9.]+) \(std-dev=([0-9.]+)\)`)
+	sockperfMessagesRegexp = regexp.MustCompile(`SentMessages=([0-9]+); ReceivedMessages=([0-9]+)`)
+)
+
+// latencyBackend measures round trip times between the pods. The server pod only accepts TCP
+// connections in the "icmp" and "tcp" modes, the probes are sent by the client pod.
+type latencyBackend struct {
+	n *Netperf
+}
+
+func (b *latencyBackend) getSpec(cr *v1alpha1.Netperf) v1alpha1.NetperfLatencySpec {
+	spec := v1alpha1.NetperfLatencySpec{}
+	if cr.Spec.Latency != nil {
+		spec = *cr.Spec.Latency
+	}
+	if spec.Mode == "" {
+		spec.Mode = v1alpha1.NetperfLatencyModeICMP
+	}
+	if spec.Count <= 0 {
+		spec.Count = defaultLatencyCount
+	}
+	if spec.IntervalMilliseconds <= 0 {
+		spec.IntervalMilliseconds = defaultLatencyIntervalMilliseconds
+	}
+	if spec.DurationSeconds <= 0 {
+		spec.DurationSeconds = defaultSockperfDurationSeconds
+	}
+	return spec
+}
+
+func (b *latencyBackend) image(cr *v1alpha1.Netperf) string {
+	if image := b.getSpec(cr).Image; image != "" {
+		return image
+	}
+	return latencyImage
+}
+
+func (b *latencyBackend) serverCommand(cr *v1alpha1.Netperf) []string {
+	port := strconv.Itoa(latencyPort)
+	if b.getSpec(cr).Mode == v1alpha1.NetperfLatencyModeSockperf {
+		return []string{"sockperf", "server", "-p", port}
+	}
+	return []string{"socat", fmt.Sprintf("TCP-LISTEN:%s,fork,reuseaddr", port), "SYSTEM:true"}
+}
+
+func (b *latencyBackend) clientCommand(cr *v1alpha1.Netperf, serverIP string) []string {
+	spec := b.getSpec(cr)
+	interval := fmt.Sprintf("%.3f", float64(spec.IntervalMilliseconds)/1000)
+	switch spec.Mode {
+	case v1alpha1.NetperfLatencyModeTCP:
+		probe := fmt.Sprintf("curl -s -o /dev/null --connect-timeout 1 -w 'connect=%%{time_connect}\\n' http://%s:%d/",
+			serverIP, latencyPort)
+		return []string{"sh", "-c", fmt.Sprintf("for i in $(seq %d); do %s; sleep %s; done", spec.Count, probe, interval)}
+	case v1alpha1.NetperfLatencyModeSockperf:
+		return []string{"sockperf", "ping-pong", "-i", serverIP, "-p", strconv.Itoa(latencyPort),
+			"-t", strconv.Itoa(spec.DurationSeconds), "--full-rtt"}
+	default:
+		return []string{"ping", "-c", strconv.Itoa(spec.Count), "-i", interval, serverIP}
+	}
+}
+
+func (b *latencyBackend) ports(cr *v1alpha1.Netperf) []networkingv1.NetworkPolicyPort {
+	// sockperf uses UDP by default, ICMP can't be allowed by network policies, so the icmp mode
+	// is rejected by validateSpec
+	if b.getSpec(cr).Mode == v1alpha1.NetperfLatencyModeSockperf {
+		return []networkingv1.NetworkPolicyPort{newPolicyPort(v1.ProtocolUDP, latencyPort)}
+	}
+	return []networkingv1.NetworkPolicyPort{newPolicyPort(v1.ProtocolTCP, latencyPort)}
+}
+
+func (b *latencyBackend) testType(cr *v1alpha1.Netperf) string {
+	switch b.getSpec(cr).Mode {
+	case v1alpha1.NetperfLatencyModeTCP:
+		return "LATENCY_TCP_CONNECT"
+	case v1alpha1.NetperfLatencyModeSockperf:
+		return "LATENCY_SOCKPERF"
+	default:
+		return "LATENCY_ICMP"
+	}
+}
+
+// parseResult fills in the latency results and the p99 latency of the statistics, so
+// the expectations and baselines can check it
+func (b *latencyBackend) parseResult(cr *v1alpha1.Netperf, output string, status *v1alpha1.NetperfStatus) error {
+	var result *v1alpha1.NetperfLatencyResult
+	var err error
+	spec := b.getSpec(cr)
+	switch spec.Mode {
+	case v1alpha1.NetperfLatencyModeTCP:
+		result, err = b.parseProbes(output, tcpConnectRegexp, 1e6, int64(spec.Count))
+	case v1alpha1.NetperfLatencyModeSockperf:
+		result, err = b.parseSockperf(output)
+	default:
+		result, err = b.parseProbes(output, pingTimeRegexp, 1e3, int64(spec.Count))
+	}
+	if err != nil {
+		return err
+	}
+	status.Latency = result
+	status.Stats = &v1alpha1.NetperfStats{P99LatencyMicroseconds: result.P99Microseconds}
+	return nil
+}
+
+// parseProbes computes the distribution of round trip times printed for each probe. Scale
+// converts the printed values to microseconds, zero values are failed probes.
+func (b *latencyBackend) parseProbes(output string, re *regexp.Regexp, scale float64, sent int64) (*v1alpha1.NetperfLatencyResult, error) {
+	var rtts []float64
+	for _, match := range re.FindAllStringSubmatch(output, -1) {
+		value, err := strconv.ParseFloat(match[1], 64)
+		if err != nil {
+			return nil, fmt.Errorf("error parsing round trip time %q: %v", match[1], err)
+		}
+		if value > 0 {
+			rtts = append(rtts, value*scale)
+		}
+	}
+	if len(rtts) == 0 {
+		return nil, fmt.Errorf("No successful probes found in the output")
+	}
+	result := b.n.getLatencyDistribution(rtts)
+	result.Sent = sent
+	result.Received = int64(len(rtts))
+	if result.Received < sent {
+		result.LossPercent = float64(sent-result.Received) / float64(sent) * 100
+	}
+	return result, nil
+}
+
+// getPercentile returns the nearest rank percentile of the sorted values
+func (n *Netperf) getPercentile(sorted []float64, percentile float64) float64 {
+	rank := int(math.Ceil(percentile/100*float64(len(sorted)))) - 1
+	if rank < 0 {
+		rank = 0
+	}
+	return sorted[rank]
+}
+
+// getLatencyDistribution summarizes the latency samples, which can't be empty
+func (n *Netperf) getLatencyDistribution(rtts []float64) *v1alpha1.NetperfLatencyResult {
+	var sum, jitter float64
+	for i, rtt := range rtts {
+		sum += rtt
+		if i > 0 {
+			jitter += math.Abs(rtt - rtts[i-1])
+		}
+	}
+	result := &v1alpha1.NetperfLatencyResult{AvgMicroseconds: sum / float64(len(rtts))}
+	if len(rtts) > 1 {
+		result.JitterMicroseconds = jitter / float64(len(rtts)-1)
+	}
+	var variance float64
+	for _, rtt := range rtts {
+		variance += (rtt - result.AvgMicroseconds) * (rtt - result.AvgMicroseconds)
+	}
+	result.StdDevMicroseconds = math.Sqrt(variance / float64(len(rtts)))
+
+	sorted := append([]float64{}, rtts...)
+	sort.Float64s(sorted)
+	result.MinMicroseconds = sorted[0]
+	result.MaxMicroseconds = sorted[len(sorted)-1]
+	result.P99Microseconds = n.getPercentile(sorted, 99)
+	return result
+}
+
+// parseSockperf reads the summary printed by sockperf ping-pong run with --full-rtt. It doesn't
+// print round trip times of single messages, so jitter isn't known.
+func (b *latencyBackend) parseSockperf(output string) (*v1alpha1.NetperfLatencyResult, error) {
+	find := func(re *regexp.Regexp, group int) (float64, error) {
+		match := re.FindStringSubmatch(output)
+		if match == nil {
+			return 0, fmt.Errorf("No sockperf results found in the output")
+		}
+		return strconv.ParseFloat(match[group], 64)
+	}
+	result := &v1alpha1.NetperfLatencyResult{}
+	for _, value := range []struct {
+		re    *regexp.Regexp
+		group int
+		field *float64
+	}{
+		{sockperfMinRegexp, 1, &result.MinMicroseconds},
+		{sockperfAvgRegexp, 1, &result.AvgMicroseconds},
+		{sockperfAvgRegexp, 2, &result.StdDevMicroseconds},
+		{sockperfPercentileRegexp, 1, &result.P99Microseconds},
+		{sockperfMaxRegexp, 1, &result.MaxMicroseconds},
+	} {
+		v, err := find(value.re, value.group)
+		if err != nil {
+			return nil, err
+		}
+		*value.field = v
+	}
+	if match := sockperfMessagesRegexp.FindStringSubmatch(output); match != nil {
+		result.Sent, _ = strconv.ParseInt(match[1], 10, 64)
+		result.Received, _ = strconv.ParseInt(match[2], 10, 64)
+		if result.Sent > 0 && result.Received < result.Sent {
+			result.LossPercent = float64(result.Sent-result.Received) / float64(result.Sent) * 100
+		}
+	}
+	return result, nil
+}
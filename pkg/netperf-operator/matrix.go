@@ -0,0 +1,207 @@
+package operator
+
+import (
+	"fmt"
+	"math/rand"
+	"reflect"
+	"sort"
+	"time"
+
+	"github.com/piontec/netperf-operator/pkg/apis/app/v1alpha1"
+	"github.com/sirupsen/logrus"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+func (n *Netperf) HandleNetperfMatrix(o *v1alpha1.NetperfMatrix, deleted bool) error {
+	logrus.Debugf("New NetperfMatrix event, name: %s, deleted: %v, status: %v", o.Name, deleted, o.Status.Status)
+	if deleted {
+		// child Netperf objects are removed by the garbage collector, as the matrix is their owner
+		return nil
+	}
+	switch o.Status.Status {
+	case v1alpha1.NetperfMatrixPhaseInitial:
+		return n.startNetperfMatrix(o)
+	case v1alpha1.NetperfMatrixPhaseRunning:
+		return n.updateNetperfMatrix(o)
+	default:
+		logrus.Debugf("Nothing needed to do for update event on NetperfMatrix %s in state %s",
+			o.Name, o.Status.Status)
+		return nil
+	}
+}
+
+func (n *Netperf) getMatrixNodes(cr *v1alpha1.NetperfMatrix) ([]string, error) {
+	client := n.provider.GetKubeClient()
+	nodeList, err := client.CoreV1().Nodes().List(metav1.ListOptions{
+		LabelSelector: labels.SelectorFromSet(cr.Spec.NodeSelector).String(),
+	})
+	if err != nil {
+		return nil, err
+	}
+	var nodes []string
+	for _, node := range nodeList.Items {
+		nodes = append(nodes, node.Name)
+	}
+	sort.Strings(nodes)
+	return nodes, nil
+}
+
+// getMatrixPairs returns all ordered pairs of different nodes. If maxPairs is set, only a random
+// sample of that size is returned.
+func (n *Netperf) getMatrixPairs(nodes []string, maxPairs int, random *rand.Rand) []v1alpha1.NetperfMatrixResult {
+	var pairs []v1alpha1.NetperfMatrixResult
+	for _, server := range nodes {
+		for _, client := range nodes {
+			if server == client {
+				continue
+			}
+			pairs = append(pairs, v1alpha1.NetperfMatrixResult{
+				ServerNode: server,
+				ClientNode: client,
+				Status:     v1alpha1.NetperfMatrixPairPending,
+			})
+		}
+	}
+	if maxPairs <= 0 || maxPairs >= len(pairs) {
+		return pairs
+	}
+
+	chosen := random.Perm(len(pairs))[:maxPairs]
+	sort.Ints(chosen)
+	sample := make([]v1alpha1.NetperfMatrixResult, 0, maxPairs)
+	for _, i := range chosen {
+		sample = append(sample, pairs[i])
+	}
+	return sample
+}
+
+func (n *Netperf) startNetperfMatrix(cr *v1alpha1.NetperfMatrix) error {
+	nodes, err := n.getMatrixNodes(cr)
+	if err != nil {
+		logrus.Errorf("Failed to list nodes for NetperfMatrix %s/%s: %v", cr.Namespace, cr.Name, err)
+		return err
+	}
+	c := cr.DeepCopy()
+	c.Status.Nodes = nodes
+	c.Status.Results = n.getMatrixPairs(nodes, cr.Spec.MaxPairs, rand.New(rand.NewSource(time.Now().UnixNano())))
+	if len(c.Status.Results) == 0 {
+		logrus.Errorf("NetperfMatrix %s/%s selects less than 2 nodes, nothing to test", cr.Namespace, cr.Name)
+		c.Status.Status = v1alpha1.NetperfMatrixPhaseError
+		return n.provider.Update(c)
+	}
+	logrus.Debugf("NetperfMatrix %s will test %d node pairs", cr.Name, len(c.Status.Results))
+	c.Status.Status = v1alpha1.NetperfMatrixPhaseRunning
+	return n.provider.Update(c)
+}
+
+func (n *Netperf) getMatrixConcurrency(cr *v1alpha1.NetperfMatrix) int {
+	if cr.Spec.Concurrency < 1 {
+		return 1
+	}
+	return cr.Spec.Concurrency
+}
+
+func (n *Netperf) isNetperfFinished(status string) bool {
+	return status == v1alpha1.NetperfPhaseDone || status == v1alpha1.NetperfPhaseError
+}
+
+func (n *Netperf) newMatrixNetperf(cr *v1alpha1.NetperfMatrix, index int) *v1alpha1.Netperf {
+	pair := cr.Status.Results[index]
+	spec := cr.Spec.Template
+	spec.ServerNode = pair.ServerNode
+	spec.ClientNode = pair.ClientNode
+	return &v1alpha1.Netperf{
+		TypeMeta: metav1.TypeMeta{
+			Kind:       "Netperf",
+			APIVersion: "app.example.com/v1alpha1",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      fmt.Sprintf("%s-%d", cr.Name, index),
+			Namespace: cr.Namespace,
+			OwnerReferences: []metav1.OwnerReference{
+				*metav1.NewControllerRef(cr, schema.GroupVersionKind{
+					Group:   v1alpha1.SchemeGroupVersion.Group,
+					Version: v1alpha1.SchemeGroupVersion.Version,
+					Kind:    "NetperfMatrix",
+				}),
+			},
+			Labels: map[string]string{
+				"app":            "netperf-operator",
+				"netperf-matrix": cr.Name,
+			},
+		},
+		Spec: spec,
+	}
+}
+
+// updateNetperfMatrix collects results of finished Netperf objects and starts new ones,
+// so that no more than the configured number of tests run at the same time
+func (n *Netperf) updateNetperfMatrix(cr *v1alpha1.NetperfMatrix) error {
+	c := cr.DeepCopy()
+	running, finished := 0, 0
+	for i := range c.Status.Results {
+		result := &c.Status.Results[i]
+		if result.Netperf == "" {
+			continue
+		}
+		if n.isNetperfFinished(result.Status) {
+			finished++
+			continue
+		}
+		netperf, err := n.getNetperfByName(result.Netperf, cr.Namespace)
+		if err != nil && errors.IsNotFound(err) {
+			logrus.Warnf("Netperf %s/%s of NetperfMatrix %s is gone", cr.Namespace, result.Netperf, cr.Name)
+			result.Status = v1alpha1.NetperfPhaseError
+			finished++
+			continue
+		}
+		if err != nil {
+			logrus.Errorf("Error fetching Netperf %s/%s of NetperfMatrix %s: %v", cr.Namespace,
+				result.Netperf, cr.Name, err)
+			running++
+			continue
+		}
+		if netperf.Status.Status != v1alpha1.NetperfPhaseInitial {
+			result.Status = netperf.Status.Status
+		}
+		result.SpeedBitsPerSec = netperf.Status.SpeedBitsPerSec
+		result.P99LatencyMicroseconds = n.getP99Latency(netperf)
+		if n.isNetperfFinished(result.Status) {
+			finished++
+		} else {
+			running++
+		}
+	}
+
+	for i := range c.Status.Results {
+		if running >= n.getMatrixConcurrency(cr) {
+			break
+		}
+		result := &c.Status.Results[i]
+		if result.Netperf != "" {
+			continue
+		}
+		netperf := n.newMatrixNetperf(c, i)
+		if err := n.provider.Create(netperf); err != nil && !errors.IsAlreadyExists(err) {
+			logrus.Errorf("Failed to create Netperf %s/%s for NetperfMatrix %s: %v", netperf.Namespace,
+				netperf.Name, cr.Name, err)
+			return err
+		}
+		logrus.Debugf("Started Netperf %s for nodes %s -> %s", netperf.Name, result.ClientNode, result.ServerNode)
+		result.Netperf = netperf.Name
+		result.Status = v1alpha1.NetperfMatrixPairStarted
+		running++
+	}
+
+	if finished == len(c.Status.Results) {
+		logrus.Debugf("All tests of NetperfMatrix %s are finished", cr.Name)
+		c.Status.Status = v1alpha1.NetperfMatrixPhaseDone
+	}
+	if reflect.DeepEqual(cr.Status, c.Status) {
+		return nil
+	}
+	return n.provider.Update(c)
+}
@@ -0,0 +1,98 @@
+package operator
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+
+	"github.com/piontec/netperf-operator/pkg/apis/app/v1alpha1"
+	"k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+)
+
+const (
+	mtuImage = latencyImage
+	// the server pod reports MTU of its interface on this port
+	mtuPort = latencyPort
+	// IPv4 and ICMP headers added to the ping payload
+	mtuHeadersSize   = 28
+	minMTU           = 68
+	eventMTUMismatch = "MTUMismatch"
+	mtuInterfaceFile = "/sys/class/net/eth0/mtu"
+	// mtuClientScript searches for the largest packet which gets through with the don't fragment
+	// bit set, then checks if packets of the interface MTU size get through when fragmented
+	mtuClientScript = `server=%[1]s
+mtu=$(cat %[2]s)
+echo client_mtu=$mtu
+echo server_mtu=$(nc -w 2 $server %[3]d)
+probe() { ping -c 3 -i 0.2 -W 1 -M $1 -s $(($2 - %[4]d)) $server >/dev/null 2>&1; }
+probe do %[5]d || { echo "server $server is unreachable"; exit 1; }
+lo=%[5]d
+hi=$mtu
+while [ $lo -lt $hi ]; do
+  size=$(((lo + hi + 1) / 2))
+  if probe do $size; then echo probe=$size ok; lo=$size; else echo probe=$size failed; hi=$((size - 1)); fi
+done
+echo path_mtu=$lo
+if probe dont $mtu; then echo fragmentation=ok; else echo fragmentation=failed; fi
+`
+)
+
+var mtuResultRegexp = regexp.MustCompile(`(?m)^(client_mtu|server_mtu|path_mtu|fragmentation)=(\S*)$`)
+
+// mtuBackend determines the path MTU between the pods using ping with the don't fragment bit
+type mtuBackend struct {
+	n *Netperf
+}
+
+func (b *mtuBackend) image(cr *v1alpha1.Netperf) string {
+	return mtuImage
+}
+
+func (b *mtuBackend) serverCommand(cr *v1alpha1.Netperf) []string {
+	return []string{"socat", fmt.Sprintf("TCP-LISTEN:%d,fork,reuseaddr", mtuPort), "SYSTEM:cat " + mtuInterfaceFile}
+}
+
+func (b *mtuBackend) clientCommand(cr *v1alpha1.Netperf, serverIP string) []string {
+	return []string{"sh", "-c", fmt.Sprintf(mtuClientScript, serverIP, mtuInterfaceFile, mtuPort, mtuHeadersSize, minMTU)}
+}
+
+func (b *mtuBackend) ports(cr *v1alpha1.Netperf) []networkingv1.NetworkPolicyPort {
+	return []networkingv1.NetworkPolicyPort{newPolicyPort(v1.ProtocolTCP, mtuPort)}
+}
+
+func (b *mtuBackend) testType(cr *v1alpha1.Netperf) string {
+	return "PATH_MTU"
+}
+
+func (b *mtuBackend) parseResult(cr *v1alpha1.Netperf, output string, status *v1alpha1.NetperfStatus) error {
+	values := map[string]string{}
+	for _, match := range mtuResultRegexp.FindAllStringSubmatch(output, -1) {
+		values[match[1]] = match[2]
+	}
+	if values["path_mtu"] == "" {
+		return fmt.Errorf("No path MTU found in the output")
+	}
+	result := &v1alpha1.NetperfMTUResult{FragmentationWorks: values["fragmentation"] == "ok"}
+	var err error
+	if result.PathMTU, err = strconv.Atoi(values["path_mtu"]); err != nil {
+		return fmt.Errorf("error parsing path MTU: %v", err)
+	}
+	if result.ClientInterfaceMTU, err = strconv.Atoi(values["client_mtu"]); err != nil {
+		return fmt.Errorf("error parsing MTU of the client: %v", err)
+	}
+	// MTU of the server is only informative, it's missing if the server couldn't be asked for it
+	result.ServerInterfaceMTU, _ = strconv.Atoi(values["server_mtu"])
+
+	interfaceMTU := result.ClientInterfaceMTU
+	if result.ServerInterfaceMTU > 0 && result.ServerInterfaceMTU < interfaceMTU {
+		interfaceMTU = result.ServerInterfaceMTU
+	}
+	result.Mismatch = result.PathMTU < interfaceMTU
+	if result.Mismatch {
+		b.n.recorder.Eventf(cr, v1.EventTypeWarning, eventMTUMismatch,
+			"Path MTU %d is lower than MTU %d of the pod interfaces", result.PathMTU, interfaceMTU)
+	}
+	status.MTU = result
+	return nil
+}
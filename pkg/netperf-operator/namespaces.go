@@ -0,0 +1,95 @@
+package operator
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/piontec/netperf-operator/pkg/apis/app/v1alpha1"
+	"github.com/sirupsen/logrus"
+	"k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+const (
+	// netperfAnnotation references the Netperf object of a test pod as "namespace/name". Pods in
+	// other namespaces than their Netperf can't be owned by it.
+	netperfAnnotation = "app.example.com/netperf"
+	// namespaceNameLabel is set on namespaces by Kubernetes since 1.21
+	namespaceNameLabel = "kubernetes.io/metadata.name"
+)
+
+// getPodNamespace returns the namespace of the server or the client pod, the namespace of the
+// Netperf object if it's not set
+func (n *Netperf) getPodNamespace(cr *v1alpha1.Netperf, npType netperfType) string {
+	namespace := cr.Spec.ClientNamespace
+	if npType == netperfTypeServer {
+		namespace = cr.Spec.ServerNamespace
+	}
+	if namespace == "" {
+		return cr.Namespace
+	}
+	return namespace
+}
+
+func (n *Netperf) isCrossNamespace(cr *v1alpha1.Netperf) bool {
+	return n.getPodNamespace(cr, netperfTypeServer) != cr.Namespace ||
+		n.getPodNamespace(cr, netperfTypeClient) != cr.Namespace
+}
+
+// getOwnerReferences makes the Netperf object the owner of a resource in the namespace, so the
+// resource is garbage collected. Resources in other namespaces are deleted by the operator.
+func (n *Netperf) getOwnerReferences(cr *v1alpha1.Netperf, namespace string) []metav1.OwnerReference {
+	if namespace != cr.Namespace {
+		return nil
+	}
+	return []metav1.OwnerReference{
+		*metav1.NewControllerRef(cr, schema.GroupVersionKind{
+			Group:   v1alpha1.SchemeGroupVersion.Group,
+			Version: v1alpha1.SchemeGroupVersion.Version,
+			Kind:    "Netperf",
+		}),
+	}
+}
+
+// getNetperfReference returns namespace and name of the Netperf object the pod belongs to. The
+// annotation is only used for pods labeled as test pods, the caller still has to check that the
+// pod is in the namespace and has the labels of the referenced Netperf.
+func (n *Netperf) getNetperfReference(pod *v1.Pod) (namespace, name string, found bool) {
+	if reference, ok := pod.Annotations[netperfAnnotation]; ok {
+		if pod.Labels["app"] != "netperf-operator" || pod.Labels["netperf-id"] == "" {
+			logrus.Warnf("Pod %s/%s has %s annotation, but isn't labeled as a test pod", pod.Namespace, pod.Name,
+				netperfAnnotation)
+			return "", "", false
+		}
+		parts := strings.SplitN(reference, "/", 2)
+		if len(parts) != 2 {
+			logrus.Warnf("Pod %s/%s has invalid %s annotation %q", pod.Namespace, pod.Name, netperfAnnotation, reference)
+			return "", "", false
+		}
+		return parts[0], parts[1], true
+	}
+	if len(pod.OwnerReferences) == 0 || pod.OwnerReferences[0].Kind != "Netperf" {
+		return "", "", false
+	}
+	if pod.OwnerReferences[0].UID == "" {
+		logrus.Warnf("Pod %s/%s has owner of type Netperf, but UID is unknown", pod.Namespace, pod.Name)
+	}
+	return pod.Namespace, pod.OwnerReferences[0].Name, true
+}
+
+func (n *Netperf) getNetperfAnnotations(cr *v1alpha1.Netperf) map[string]string {
+	return map[string]string{netperfAnnotation: fmt.Sprintf("%s/%s", cr.Namespace, cr.Name)}
+}
+
+// getNetperfPeer selects the test pod of the given type in its namespace
+func (n *Netperf) getNetperfPeer(cr *v1alpha1.Netperf, npType netperfType) networkingv1.NetworkPolicyPeer {
+	peer := networkingv1.NetworkPolicyPeer{PodSelector: n.getNetperfPodSelector(cr, npType)}
+	if n.isCrossNamespace(cr) {
+		peer.NamespaceSelector = &metav1.LabelSelector{
+			MatchLabels: map[string]string{namespaceNameLabel: n.getPodNamespace(cr, npType)},
+		}
+	}
+	return peer
+}
@@ -0,0 +1,126 @@
+package operator
+
+import (
+	"fmt"
+
+	"github.com/piontec/netperf-operator/pkg/apis/app/v1alpha1"
+	"github.com/sirupsen/logrus"
+	"k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	netperfControlPort = 12865
+	netperfDataPort    = 12866
+)
+
+func (n *Netperf) usesNetworkPolicy(cr *v1alpha1.Netperf) bool {
+	return cr.Spec.NetworkPolicy == v1alpha1.NetperfNetworkPolicyAllow ||
+		cr.Spec.NetworkPolicy == v1alpha1.NetperfNetworkPolicyEnforced
+}
+
+func (n *Netperf) getNetworkPolicyPorts(cr *v1alpha1.Netperf) []networkingv1.NetworkPolicyPort {
+	return n.getBackend(cr).ports(cr)
+}
+
+func (n *Netperf) getNetperfPodSelector(cr *v1alpha1.Netperf, npType netperfType) *metav1.LabelSelector {
+	return &metav1.LabelSelector{
+		MatchLabels: map[string]string{
+			"netperf-id":   n.getNetperfSuffix(cr),
+			"netperf-type": fmt.Sprint(npType),
+		},
+	}
+}
+
+func (n *Netperf) newNetworkPolicy(cr *v1alpha1.Netperf, npType netperfType) *networkingv1.NetworkPolicy {
+	policy := &networkingv1.NetworkPolicy{
+		TypeMeta: metav1.TypeMeta{
+			Kind:       "NetworkPolicy",
+			APIVersion: "networking.k8s.io/v1",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:            fmt.Sprintf("netperf-%s-%s", npType, n.getNetperfSuffix(cr)),
+			Namespace:       n.getPodNamespace(cr, npType),
+			OwnerReferences: n.getOwnerReferences(cr, n.getPodNamespace(cr, npType)),
+			Labels: map[string]string{
+				"app": "netperf-operator",
+			},
+		},
+	}
+
+	// server only accepts traffic from the client, client can only send it to the server
+	if npType == netperfTypeServer {
+		policy.Spec = networkingv1.NetworkPolicySpec{
+			PodSelector: *n.getNetperfPodSelector(cr, netperfTypeServer),
+			PolicyTypes: []networkingv1.PolicyType{networkingv1.PolicyTypeIngress},
+			Ingress: []networkingv1.NetworkPolicyIngressRule{
+				{
+					Ports: n.getNetworkPolicyPorts(cr),
+					From:  []networkingv1.NetworkPolicyPeer{n.getNetperfPeer(cr, netperfTypeClient)},
+				},
+			},
+		}
+	} else {
+		policy.Spec = networkingv1.NetworkPolicySpec{
+			PodSelector: *n.getNetperfPodSelector(cr, netperfTypeClient),
+			PolicyTypes: []networkingv1.PolicyType{networkingv1.PolicyTypeEgress},
+			Egress: []networkingv1.NetworkPolicyEgressRule{
+				{
+					Ports: n.getNetworkPolicyPorts(cr),
+					To:    []networkingv1.NetworkPolicyPeer{n.getNetperfPeer(cr, netperfTypeServer)},
+				},
+			},
+		}
+		if n.needsDNSEgress(cr) {
+			policy.Spec.Egress = append(policy.Spec.Egress, networkingv1.NetworkPolicyEgressRule{Ports: n.getDNSPolicyPorts()})
+		}
+	}
+	return policy
+}
+
+func (n *Netperf) createNetworkPolicies(cr *v1alpha1.Netperf) error {
+	for _, npType := range []netperfType{netperfTypeServer, netperfTypeClient} {
+		policy := n.newNetworkPolicy(cr, npType)
+		err := n.provider.Create(policy)
+		if err != nil && !errors.IsAlreadyExists(err) {
+			logrus.Errorf("Failed to create network policy %s/%s: %v", policy.Namespace, policy.Name, err)
+			return err
+		}
+		logrus.Debugf("Network policy %s/%s is ready for netperf: %s", policy.Namespace, policy.Name, cr.Name)
+	}
+	return nil
+}
+
+func (n *Netperf) deleteNetworkPolicies(cr *v1alpha1.Netperf) error {
+	for _, npType := range []netperfType{netperfTypeServer, netperfTypeClient} {
+		policy := n.newNetworkPolicy(cr, npType)
+		if err := n.provider.Delete(policy); err != nil && !errors.IsNotFound(err) {
+			logrus.Debugf("Error deleting network policy %s/%s: %v", policy.Namespace, policy.Name, err)
+			return err
+		}
+	}
+	return nil
+}
+
+// startEnforcedRun records the result of the test run without network policies, then creates
+// the policies and schedules a second client pod, which is created on the next server pod event
+func (n *Netperf) startEnforcedRun(cr *v1alpha1.Netperf, clientPod *v1.Pod, throughput float64) error {
+	logrus.Debugf("Test without network policies completed for netperf %s, enforcing policies", cr.Name)
+	if err := n.provider.Delete(clientPod); err != nil && !errors.IsNotFound(err) {
+		n.updateNetperfStatus(cr, v1alpha1.NetperfPhaseError)
+		logrus.Debugf("Error deleting client pod %v: %v", clientPod.Name, err)
+		return err
+	}
+	if err := n.createNetworkPolicies(cr); err != nil {
+		n.updateNetperfStatus(cr, v1alpha1.NetperfPhaseError)
+		return err
+	}
+	netperf := cr.DeepCopy()
+	netperf.Status.UnenforcedSpeedBitsPerSec = throughput
+	netperf.Status.PolicyEnforced = true
+	netperf.Status.ClientPod = ""
+	netperf.Status.Status = v1alpha1.NetperfPhaseServer
+	return n.provider.Update(netperf)
+}
@@ -0,0 +1,62 @@
+package operator
+
+import (
+	"time"
+
+	"github.com/piontec/netperf-operator/pkg/apis/app/v1alpha1"
+	"github.com/piontec/netperf-operator/pkg/notify"
+)
+
+// newNotification describes the finished run of the test
+func (n *Netperf) newNotification(cr *v1alpha1.Netperf) notify.Notification {
+	notification := notify.Notification{
+		Event:      notify.EventCompleted,
+		Time:       time.Now(),
+		Namespace:  cr.Namespace,
+		Name:       cr.Name,
+		Run:        cr.Status.Run,
+		ServerNode: cr.Status.ServerNode,
+		ClientNode: cr.Status.ClientNode,
+		Parameters: notify.Parameters{
+			TestType:        n.getTestType(cr),
+			Direction:       n.getDirection(cr),
+			ParallelStreams: n.getStreamCount(cr),
+			NetworkPolicy:   cr.Spec.NetworkPolicy,
+		},
+		Verdict:    cr.Status.Verdict,
+		Regression: cr.Status.Regression,
+	}
+	if cr.Status.CompletionTime != nil {
+		notification.Time = cr.Status.CompletionTime.Time
+	}
+	// nodes are known only once the test is done, report the requested ones before
+	if notification.ServerNode == "" {
+		notification.ServerNode = cr.Spec.ServerNode
+	}
+	if notification.ClientNode == "" {
+		notification.ClientNode = cr.Spec.ClientNode
+	}
+
+	switch {
+	case cr.Status.Status == v1alpha1.NetperfPhaseError:
+		notification.Event = notify.EventFailed
+	case cr.Status.Regression != nil && cr.Status.Regression.Regressed:
+		notification.Event = notify.EventRegressed
+	}
+	if cr.Status.Status == v1alpha1.NetperfPhaseDone {
+		notification.Results = &notify.Results{
+			SpeedBitsPerSec:          cr.Status.SpeedBitsPerSec,
+			ClientToServerBitsPerSec: cr.Status.ClientToServerBitsPerSec,
+			ServerToClientBitsPerSec: cr.Status.ServerToClientBitsPerSec,
+			Stats:                    cr.Status.Stats,
+		}
+	}
+	return notification
+}
+
+func (n *Netperf) notifyFinished(cr *v1alpha1.Netperf) {
+	if n.notifier == nil {
+		return
+	}
+	n.notifier.Notify(n.newNotification(cr))
+}
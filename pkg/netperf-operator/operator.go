@@ -5,14 +5,18 @@ import (
 	"fmt"
 	"strconv"
 	"strings"
+	"sync"
+	"time"
 
 	"github.com/piontec/netperf-operator/pkg/apis/app/kube"
 	"github.com/piontec/netperf-operator/pkg/apis/app/v1alpha1"
+	"github.com/piontec/netperf-operator/pkg/export"
+	"github.com/piontec/netperf-operator/pkg/metrics"
+	"github.com/piontec/netperf-operator/pkg/notify"
 	"github.com/sirupsen/logrus"
 	"k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
-	"k8s.io/apimachinery/pkg/runtime/schema"
 )
 
 type netperfType string
@@ -26,15 +30,36 @@ const (
 type Netperfer interface {
 	HandleNetperf(*v1alpha1.Netperf, bool) error
 	HandlePod(*v1.Pod, bool) error
+	HandleNetperfMatrix(*v1alpha1.NetperfMatrix, bool) error
+	HandleNetperfSchedule(*v1alpha1.NetperfSchedule, bool) error
+}
+
+// Config has operator-wide settings
+type Config struct {
+	// DefaultTTL is used for Netperf objects without ttlSecondsAfterFinished, nil means they're kept forever
+	DefaultTTL *int32
+	// Notifier is told about finished tests, it's optional
+	Notifier notify.Notifier
+	// Exporters get results of all the successfully finished tests
+	Exporters []export.Exporter
 }
 
 type Netperf struct {
-	provider kube.Provider
+	provider   kube.Provider
+	recorder   kube.EventRecorder
+	defaultTTL *int32
+	notifier   notify.Notifier
+	exporters  []export.Exporter
+	exportLock sync.Mutex
 }
 
-func NewNetperf(provider kube.Provider) Netperfer {
+func NewNetperf(provider kube.Provider, recorder kube.EventRecorder, config Config) Netperfer {
 	return &Netperf{
-		provider: provider,
+		provider:   provider,
+		recorder:   recorder,
+		defaultTTL: config.DefaultTTL,
+		notifier:   config.Notifier,
+		exporters:  config.Exporters,
 	}
 }
 
@@ -47,27 +72,57 @@ func (n *Netperf) HandleNetperf(o *v1alpha1.Netperf, deleted bool) error {
 }
 
 func (n *Netperf) HandlePod(pod *v1.Pod, deleted bool) error {
-	if pod.ObjectMeta.OwnerReferences[0].Kind != "Netperf" {
+	namespace, name, found := n.getNetperfReference(pod)
+	if !found {
 		return nil
 	}
-	if pod.ObjectMeta.OwnerReferences[0].UID == "" {
-		logrus.Warnf("Pod %s/%s has owner of type Netperf, but UID is unknown", pod.Namespace, pod.Name)
-	}
 	logrus.Debugf("New pod event: %s/%s, deleted status: %v", pod.Namespace, pod.Name, deleted)
-	return n.handlePodUpdateEvent(pod)
+	return n.handlePodUpdateEvent(pod, namespace, name)
 }
 
 func (n *Netperf) deleteNetperfPods(cr *v1alpha1.Netperf) error {
 	logrus.Debugf("Netperf object %s/%s is being deleted", cr.Namespace, cr.Name)
+	metrics.ForgetNetperf(cr.Namespace, cr.Name)
+	// resources in other namespaces aren't owned by the Netperf, so they aren't garbage collected
+	if n.isCrossNamespace(cr) {
+		if err := n.deleteTestPods(cr); err != nil {
+			return err
+		}
+		if n.usesHTTPService(cr) {
+			if err := n.deleteHTTPService(cr); err != nil {
+				return err
+			}
+		}
+		if n.usesNetworkPolicy(cr) {
+			return n.deleteNetworkPolicies(cr)
+		}
+	}
 	return nil
 }
 
 func (n *Netperf) handleNetperfUpdateEvent(cr *v1alpha1.Netperf) error {
+	if n.needsRerun(cr) {
+		return n.startRerun(cr)
+	}
 	switch cr.Status.Status {
 	case v1alpha1.NetperfPhaseInitial:
+		if err := n.validateSpec(cr); err != nil {
+			return n.rejectSpec(cr, err)
+		}
 		return n.startServerPod(cr)
 	case v1alpha1.NetperfPhaseServer:
+		if timedOut, err := n.checkTimeout(cr); timedOut {
+			return err
+		}
 		return n.startServerPod(cr)
+	case v1alpha1.NetperfPhaseTest:
+		_, err := n.checkTimeout(cr)
+		return err
+	case v1alpha1.NetperfPhaseDone, v1alpha1.NetperfPhaseError:
+		if n.isNetperfExpired(cr, time.Now()) {
+			return n.deleteExpiredNetperf(cr)
+		}
+		return n.cleanupExpiredPods(cr)
 	default:
 		logrus.Debugf("Nothing needed to do for update event on Netperf %s in state %s",
 			cr.Name, cr.Status.Status)
@@ -76,7 +131,17 @@ func (n *Netperf) handleNetperfUpdateEvent(cr *v1alpha1.Netperf) error {
 }
 
 func (n *Netperf) startServerPod(cr *v1alpha1.Netperf) error {
-	serverPod := n.newNetperfPod(cr, netperfTypeServer, v1.RestartPolicyAlways, []string{})
+	if cr.Spec.NetworkPolicy == v1alpha1.NetperfNetworkPolicyAllow {
+		if err := n.createNetworkPolicies(cr); err != nil {
+			return err
+		}
+	}
+	if n.usesHTTPService(cr) {
+		if err := n.createHTTPService(cr); err != nil {
+			return err
+		}
+	}
+	serverPod := n.newNetperfPod(cr, netperfTypeServer, v1.RestartPolicyAlways, n.getBackend(cr).serverCommand(cr))
 
 	err := n.provider.Create(serverPod)
 	if err != nil && !errors.IsAlreadyExists(err) {
@@ -92,6 +157,7 @@ func (n *Netperf) startServerPod(cr *v1alpha1.Netperf) error {
 		}
 	} else {
 		logrus.Debugf("New server pod started for netperf: %s", cr.Name)
+		n.recorder.Eventf(cr, v1.EventTypeNormal, eventServerPodCreated, "Created server pod %s", serverPod.Name)
 	}
 
 	if err := n.registerNetperfServer(cr, serverPod); err != nil {
@@ -133,25 +199,47 @@ func (n *Netperf) getNetperfPodAffinity(cr *v1alpha1.Netperf, npType netperfType
 	}
 }
 
+func (n *Netperf) getNetperfSuffix(cr *v1alpha1.Netperf) string {
+	guidString := fmt.Sprint(cr.UID)
+	return strings.Split(guidString, "-")[4]
+}
+
 func (n *Netperf) getNetperfPodName(cr *v1alpha1.Netperf, npType netperfType) string {
 	var name string
-	guidString := fmt.Sprint(cr.UID)
-	suffix := strings.Split(guidString, "-")[4]
+	suffix := n.getNetperfSuffix(cr) + n.getRunSuffix(cr)
 	switch npType {
 	case netperfTypeClient:
 		name = "netperf-client-" + suffix
+		if cr.Spec.NetworkPolicy == v1alpha1.NetperfNetworkPolicyEnforced && cr.Status.PolicyEnforced {
+			name = "netperf-client-policy-" + suffix
+		}
 	case netperfTypeServer:
 		name = "netperf-server-" + suffix
 	}
+	if cr.Status.SidecarInjected {
+		name += "-sidecar"
+	}
 	return name
 }
 
+func (n *Netperf) getNetperfClientCommand(cr *v1alpha1.Netperf, serverIP string) []string {
+	if n.usesParallelCommand(cr) {
+		return n.getParallelClientCommand(cr, serverIP)
+	}
+	command := []string{"netperf", "-H", serverIP}
+	if n.getDirection(cr) == v1alpha1.NetperfDirectionServerToClient {
+		command = append(command, "-t", netperfTestMaerts)
+	}
+	return append(command, n.getNetperfOptions(cr, netperfDataPort)...)
+}
+
 func (n *Netperf) newNetperfPod(cr *v1alpha1.Netperf, npType netperfType, restartPolicy v1.RestartPolicy, command []string) *v1.Pod {
 	name := n.getNetperfPodName(cr, npType)
 	affinity := n.getNetperfPodAffinity(cr, npType)
 	labels := map[string]string{
 		"app":          "netperf-operator",
 		"netperf-type": fmt.Sprint(npType),
+		"netperf-id":   n.getNetperfSuffix(cr),
 	}
 	pod := &v1.Pod{
 		TypeMeta: metav1.TypeMeta{
@@ -159,22 +247,17 @@ func (n *Netperf) newNetperfPod(cr *v1alpha1.Netperf, npType netperfType, restar
 			APIVersion: "v1",
 		},
 		ObjectMeta: metav1.ObjectMeta{
-			Name:      name,
-			Namespace: cr.Namespace,
-			OwnerReferences: []metav1.OwnerReference{
-				*metav1.NewControllerRef(cr, schema.GroupVersionKind{
-					Group:   v1alpha1.SchemeGroupVersion.Group,
-					Version: v1alpha1.SchemeGroupVersion.Version,
-					Kind:    "Netperf",
-				}),
-			},
-			Labels: labels,
+			Name:            name,
+			Namespace:       n.getPodNamespace(cr, npType),
+			OwnerReferences: n.getOwnerReferences(cr, n.getPodNamespace(cr, npType)),
+			Labels:          labels,
+			Annotations:     n.getNetperfAnnotations(cr),
 		},
 		Spec: v1.PodSpec{
 			Containers: []v1.Container{
 				{
 					Name:    name,
-					Image:   netperfImage,
+					Image:   n.getBackend(cr).image(cr),
 					Command: command,
 				},
 			},
@@ -182,6 +265,10 @@ func (n *Netperf) newNetperfPod(cr *v1alpha1.Netperf, npType netperfType, restar
 			Affinity:      affinity,
 		},
 	}
+	if npType == netperfTypeClient {
+		n.setDNSConfig(cr, &pod.Spec)
+	}
+	n.setSidecarMetadata(cr, &pod.ObjectMeta)
 	return pod
 }
 
@@ -189,6 +276,13 @@ func (n *Netperf) registerNetperfServer(cr *v1alpha1.Netperf, serverPod *v1.Pod)
 	c := cr.DeepCopy()
 	c.Status.Status = v1alpha1.NetperfPhaseServer
 	c.Status.ServerPod = serverPod.Name
+	if c.Status.StartTime == nil {
+		now := metav1.Now()
+		c.Status.StartTime = &now
+	}
+	if cr.Spec.NetworkPolicy == v1alpha1.NetperfNetworkPolicyAllow {
+		c.Status.PolicyEnforced = true
+	}
 	return n.provider.Update(c)
 }
 
@@ -209,86 +303,148 @@ func (n *Netperf) getNetperfByName(name, namespace string) (*v1alpha1.Netperf, e
 	return cr, nil
 }
 
-func (n *Netperf) handlePodUpdateEvent(pod *v1.Pod) error {
+func (n *Netperf) handlePodUpdateEvent(pod *v1.Pod, namespace, name string) error {
 	logrus.Debugf("Trying to bind the pod %s with CR", pod.Name)
 	var cr *v1alpha1.Netperf
 	var err error
-	if cr, err = n.getNetperfByName(pod.OwnerReferences[0].Name, pod.Namespace); err != nil {
+	if cr, err = n.getNetperfByName(name, namespace); err != nil {
 		logrus.Errorf("error trying to fetch Netperf object %s/%s defined as owner of pod %s/%s: %v",
-			pod.Namespace, pod.OwnerReferences[0].Name, pod.Namespace, pod.Name, err)
+			namespace, name, pod.Namespace, pod.Name, err)
 		return nil
 	}
 
-	isServerPod := pod.Name == cr.Status.ServerPod
-	isClientPod := pod.Name == cr.Status.ClientPod
-	if !isServerPod && !isClientPod {
-		logrus.Errorf("pod with UID %s was not detected as server nor client pod of the CR",
-			pod.UID)
+	npType, found := n.getTestPodType(cr, pod)
+	if !found {
+		logrus.Errorf("pod %s/%s with UID %s was not detected as server nor client pod of the CR %s/%s",
+			pod.Namespace, pod.Name, pod.UID, cr.Namespace, cr.Name)
 		return nil
 	}
 
-	if isClientPod {
+	if npType == netperfTypeClient {
 		logrus.Debugf("This is client pod event for CR %s: pod phase: %s, pod IP: %s",
 			cr.Name, pod.Status.Phase, pod.Status.PodIP)
 		return n.handleClientPodEvent(cr, pod)
 	}
-	if isServerPod {
-		logrus.Debugf("This is server pod event for CR %s: pod phase: %s, pod IP: %s",
-			cr.Name, pod.Status.Phase, pod.Status.PodIP)
-		return n.handleServerPodEvent(cr, pod)
-	}
+	logrus.Debugf("This is server pod event for CR %s: pod phase: %s, pod IP: %s",
+		cr.Name, pod.Status.Phase, pod.Status.PodIP)
+	return n.handleServerPodEvent(cr, pod)
+}
 
-	return nil
+// getTestPodType tells if the pod is the current server or client pod of the Netperf. The
+// annotation referencing the Netperf can be set by any pod, so the pod has to be in the expected
+// namespace and have the labels of the test too.
+func (n *Netperf) getTestPodType(cr *v1alpha1.Netperf, pod *v1.Pod) (netperfType, bool) {
+	pods := map[netperfType]string{netperfTypeServer: cr.Status.ServerPod, netperfTypeClient: cr.Status.ClientPod}
+	for npType, name := range pods {
+		if name == "" || pod.Name != name || pod.Namespace != n.getPodNamespace(cr, npType) {
+			continue
+		}
+		if pod.Labels["netperf-id"] != n.getNetperfSuffix(cr) || pod.Labels["netperf-type"] != fmt.Sprint(npType) {
+			continue
+		}
+		return npType, true
+	}
+	return "", false
 }
 
 func (n *Netperf) handleClientPodEvent(cr *v1alpha1.Netperf, pod *v1.Pod) error {
-	if pod.Status.Phase == v1.PodRunning {
+	succeeded := n.isClientPodSucceeded(pod)
+	if pod.Status.Phase == v1.PodRunning && !succeeded {
 		logrus.Debugf("Client pod is running")
 		return nil
 	}
+	if pod.Status.Phase == v1.PodFailed {
+		return n.handleFailedPod(cr, pod)
+	}
 
-	if pod.Status.Phase == v1.PodSucceeded && cr.Status.Status != v1alpha1.NetperfPhaseDone {
+	if succeeded && !n.isNetperfFinished(cr.Status.Status) {
 		logrus.Debugf("Test completed, parsing results")
-		res := n.getLogFromClientPod(pod)
-		throughput, convErr := n.parseNetperfResult(res)
-		if convErr != nil {
+		res := n.getLogFromPod(pod)
+		if output := n.saveTestOutput(cr, pod, res); output != "" {
+			cr = cr.DeepCopy()
+			cr.Status.Output = output
+		}
+		netperf := cr.DeepCopy()
+		if convErr := n.getBackend(cr).parseResult(cr, res, &netperf.Status); convErr != nil {
+			n.recorder.Eventf(cr, v1.EventTypeWarning, eventParseFailed, "Failed to parse output of pod %s: %v",
+				pod.Name, convErr)
 			n.updateNetperfStatus(cr, v1alpha1.NetperfPhaseError)
-			return fmt.Errorf("error trying to convert test result to float: %v", convErr)
+			return convErr
+		}
+
+		if cr.Spec.NetworkPolicy == v1alpha1.NetperfNetworkPolicyEnforced && !cr.Status.PolicyEnforced {
+			return n.startEnforcedRun(cr, pod, netperf.Status.SpeedBitsPerSec)
+		}
+		if n.needsSidecarRun(cr) {
+			return n.startSidecarRun(cr, &netperf.Status)
 		}
 
-		serverPod, err := n.getPodByName(cr.Status.ServerPod, cr.Namespace)
+		serverPod, err := n.getPodByName(cr.Status.ServerPod, n.getPodNamespace(cr, netperfTypeServer))
 		if err != nil {
 			n.updateNetperfStatus(cr, v1alpha1.NetperfPhaseError)
 			logrus.Errorf("Error fetching pod %v by name: %v. Won't delete Netperf.", cr.Status.ServerPod, err)
 			return err
 		}
-		logrus.Debug("Test completed, deleting resources")
-		if err = n.provider.Delete(pod); err != nil {
-			n.updateNetperfStatus(cr, v1alpha1.NetperfPhaseError)
-			logrus.Debugf("Error deleting client pod %v: %v", pod.Name, err)
-			return err
+		if n.shouldCleanup(cr, v1alpha1.NetperfPhaseDone) {
+			logrus.Debug("Test completed, deleting resources")
+			if err = n.deleteTestResources(cr); err != nil {
+				n.updateNetperfStatus(cr, v1alpha1.NetperfPhaseError)
+				return err
+			}
+			netperf.Status.CleanedUp = true
 		}
-		if err = n.provider.Delete(serverPod); err != nil {
-			n.updateNetperfStatus(cr, v1alpha1.NetperfPhaseError)
-			logrus.Debugf("Error deleting server pod %v: %v", serverPod.Name, err)
+		netperf.Status.ServerNode = serverPod.Spec.NodeName
+		netperf.Status.ClientNode = pod.Spec.NodeName
+		netperf.Status.Status = v1alpha1.NetperfPhaseDone
+		n.setSidecarOverhead(&netperf.Status)
+		n.setVerdict(cr, &netperf.Status)
+		n.checkRegression(cr, &netperf.Status)
+		n.finishRun(netperf, v1alpha1.NetperfPhaseDone)
+		if err = n.provider.Update(netperf); err != nil {
 			return err
 		}
-		netperf := cr.DeepCopy()
-		netperf.Status.SpeedBitsPerSec = throughput
-		netperf.Status.Status = v1alpha1.NetperfPhaseDone
-		return n.provider.Update(netperf)
+		n.recorder.Eventf(cr, v1.EventTypeNormal, eventTestCompleted, "Test completed, %s",
+			n.getResultSummary(netperf))
+		n.notifyFinished(netperf)
+		n.exportResult(netperf)
+		metrics.ObserveResult(cr.Namespace, cr.Name, serverPod.Spec.NodeName, pod.Spec.NodeName, n.getTestType(cr),
+			n.getThroughput(netperf), n.getP99Latency(netperf), n.getRunDuration(cr))
+		return nil
 	}
 
 	return nil
 }
 
 func (n *Netperf) updateNetperfStatus(resource *v1alpha1.Netperf, status string) error {
+	if status == v1alpha1.NetperfPhaseError {
+		metrics.ObserveOutcome(n.getTestType(resource), metrics.OutcomeError, n.getRunDuration(resource))
+	}
 	netperf := resource.DeepCopy()
-	netperf.Status.Status = v1alpha1.NetperfPhaseDone
-	return n.provider.Update(netperf)
+	if status == v1alpha1.NetperfPhaseError && n.shouldCleanup(resource, status) {
+		if err := n.deleteTestResources(resource); err == nil {
+			netperf.Status.CleanedUp = true
+		}
+	}
+	if n.isNetperfFinished(status) {
+		netperf.Status.Status = status
+		n.setVerdict(resource, &netperf.Status)
+		n.finishRun(netperf, status)
+	} else {
+		netperf.Status.Status = status
+	}
+	if err := n.provider.Update(netperf); err != nil {
+		return err
+	}
+	if status == v1alpha1.NetperfPhaseError {
+		n.notifyFinished(netperf)
+	}
+	return nil
 }
 
 func (n *Netperf) parseNetperfResult(result string) (float64, error) {
+	if throughput, found := n.parseKeyvalThroughput(result); found {
+		return throughput, nil
+	}
 	lines := strings.Split(result, "\n")
 	if len(lines) < 7 {
 		return 0, fmt.Errorf("Bad netperf command output")
@@ -312,13 +468,18 @@ func (n *Netperf) getPodByName(name, namespace string) (*v1.Pod, error) {
 	return pod, err
 }
 
-func (n *Netperf) getLogFromClientPod(pod *v1.Pod) string {
+func (n *Netperf) getLogFromPod(pod *v1.Pod) string {
 	client := n.provider.GetKubeClient()
-	logOptions := &v1.PodLogOptions{}
+	if client == nil {
+		return ""
+	}
+	// the test container is named after the pod, there might be sidecars too
+	logOptions := &v1.PodLogOptions{Container: pod.Name}
 	req := client.CoreV1().Pods(pod.Namespace).GetLogs(pod.Name, logOptions)
 	rc, err := req.Stream()
 	if err != nil {
 		logrus.Errorf("Client error: %v", err)
+		return ""
 	}
 	defer rc.Close()
 	buf := new(bytes.Buffer)
@@ -327,6 +488,9 @@ func (n *Netperf) getLogFromClientPod(pod *v1.Pod) string {
 }
 
 func (n *Netperf) handleServerPodEvent(cr *v1alpha1.Netperf, pod *v1.Pod) error {
+	if pod.Status.Phase == v1.PodFailed {
+		return n.handleFailedPod(cr, pod)
+	}
 	if pod.Status.Phase != v1.PodRunning {
 		logrus.Debugf("Server pod is not running yet")
 		return nil
@@ -338,7 +502,8 @@ func (n *Netperf) handleServerPodEvent(cr *v1alpha1.Netperf, pod *v1.Pod) error
 	}
 
 	logrus.Debugf("Creating client pod for netperf: %v", cr.Name)
-	clientPod := n.newNetperfPod(cr, netperfTypeClient, v1.RestartPolicyOnFailure, []string{"netperf", "-H", pod.Status.PodIP})
+	clientPod := n.newNetperfPod(cr, netperfTypeClient, v1.RestartPolicyOnFailure,
+		n.getBackend(cr).clientCommand(cr, pod.Status.PodIP))
 	err := n.provider.Create(clientPod)
 	if err != nil && !errors.IsAlreadyExists(err) {
 		logrus.Errorf("Failed to create client pod : %v", err)
@@ -353,6 +518,7 @@ func (n *Netperf) handleServerPodEvent(cr *v1alpha1.Netperf, pod *v1.Pod) error
 		}
 	} else {
 		logrus.Debugf("New client pod started: %s/%s", clientPod.Namespace, clientPod.Name)
+		n.recorder.Eventf(cr, v1.EventTypeNormal, eventClientPodCreated, "Created client pod %s", clientPod.Name)
 	}
 	c := cr.DeepCopy()
 	c.Status.Status = v1alpha1.NetperfPhaseTest
@@ -1,10 +1,22 @@
 package operator
 
 import (
+	"fmt"
+	"math"
+	"math/rand"
+	"reflect"
+	"strings"
 	"testing"
+	"time"
+	"unicode/utf8"
 
 	"github.com/piontec/netperf-operator/pkg/apis/app/fakekube"
 	"github.com/piontec/netperf-operator/pkg/apis/app/kube"
+	"github.com/piontec/netperf-operator/pkg/apis/app/v1alpha1"
+	"github.com/piontec/netperf-operator/pkg/cron"
+	"k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
 func TestNetperf_parseNetperfResult(t *testing.T) {
@@ -45,3 +57,1177 @@ func TestNetperf_parseNetperfResult(t *testing.T) {
 		})
 	}
 }
+
+func TestNetperf_getNetperfClientCommand(t *testing.T) {
+	tests := []struct {
+		name      string
+		policy    string
+		direction string
+		want      []string
+	}{
+		{
+			name:   "No network policy",
+			policy: v1alpha1.NetperfNetworkPolicyNone,
+			want:   []string{"netperf", "-H", "10.0.0.1"},
+		},
+		{
+			name:      "Server to client",
+			direction: v1alpha1.NetperfDirectionServerToClient,
+			want:      []string{"netperf", "-H", "10.0.0.1", "-t", "TCP_MAERTS"},
+		},
+		{
+			name:   "Allow network policy",
+			policy: v1alpha1.NetperfNetworkPolicyAllow,
+			want:   []string{"netperf", "-H", "10.0.0.1", "--", "-P", ",12866"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			n := &Netperf{
+				provider: fakekube.NewFakeProvider(),
+			}
+			cr := &v1alpha1.Netperf{Spec: v1alpha1.NetperfSpec{NetworkPolicy: tt.policy, Direction: tt.direction}}
+			if got := n.getNetperfClientCommand(cr, "10.0.0.1"); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Netperf.getNetperfClientCommand() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNetperf_parseParallelNetperfResult(t *testing.T) {
+	tests := []struct {
+		name    string
+		result  string
+		cr      *v1alpha1.Netperf
+		want    []v1alpha1.NetperfStreamResult
+		wantErr bool
+	}{
+		{
+			name:   "Two streams",
+			result: "stream 1:  87380  16384  16384    10.00    4693.62\nstream 2:  87380  16384  16384    10.00    4601.10\n",
+			cr:     &v1alpha1.Netperf{Spec: v1alpha1.NetperfSpec{ParallelStreams: 2}},
+			want: []v1alpha1.NetperfStreamResult{
+				{Stream: 1, Direction: v1alpha1.NetperfDirectionClientToServer, SpeedBitsPerSec: 4693.62},
+				{Stream: 2, Direction: v1alpha1.NetperfDirectionClientToServer, SpeedBitsPerSec: 4601.10},
+			},
+		},
+		{
+			name:   "Bidirectional",
+			result: "stream 1:  87380  16384  16384    10.00    9387.24\nstream 2:  87380  16384  16384    10.00    3120.55\n",
+			cr:     &v1alpha1.Netperf{Spec: v1alpha1.NetperfSpec{Direction: v1alpha1.NetperfDirectionBidirectional}},
+			want: []v1alpha1.NetperfStreamResult{
+				{Stream: 1, Direction: v1alpha1.NetperfDirectionClientToServer, SpeedBitsPerSec: 9387.24},
+				{Stream: 2, Direction: v1alpha1.NetperfDirectionServerToClient, SpeedBitsPerSec: 3120.55},
+			},
+		},
+		{
+			name:    "Missing stream",
+			result:  "stream 1:  87380  16384  16384    10.00    4693.62\n",
+			cr:      &v1alpha1.Netperf{Spec: v1alpha1.NetperfSpec{ParallelStreams: 2}},
+			wantErr: true,
+		},
+		{
+			name:    "Netperf error",
+			result:  "stream 1:  87380  16384  16384    10.00    4693.62\nstream 2: establish control: are you sure there is a netserver listening\n",
+			cr:      &v1alpha1.Netperf{Spec: v1alpha1.NetperfSpec{ParallelStreams: 2}},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			n := &Netperf{
+				provider: fakekube.NewFakeProvider(),
+			}
+			got, err := n.parseParallelNetperfResult(tt.result, n.getNetperfStreams(tt.cr))
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Netperf.parseParallelNetperfResult() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Netperf.parseParallelNetperfResult() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNetperf_getStreamsFairness(t *testing.T) {
+	n := &Netperf{
+		provider: fakekube.NewFakeProvider(),
+	}
+	streams := []v1alpha1.NetperfStreamResult{
+		{Stream: 1, Direction: v1alpha1.NetperfDirectionClientToServer, SpeedBitsPerSec: 300},
+		{Stream: 2, Direction: v1alpha1.NetperfDirectionClientToServer, SpeedBitsPerSec: 100},
+		{Stream: 3, Direction: v1alpha1.NetperfDirectionServerToClient, SpeedBitsPerSec: 50},
+		{Stream: 4, Direction: v1alpha1.NetperfDirectionServerToClient, SpeedBitsPerSec: 50},
+	}
+	got := n.getStreamsFairness(streams, v1alpha1.NetperfDirectionClientToServer)
+	want := &v1alpha1.NetperfFairness{
+		MinBitsPerSec:    100,
+		MaxBitsPerSec:    300,
+		MeanBitsPerSec:   200,
+		StdDevBitsPerSec: 100,
+		JainIndex:        0.8,
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Netperf.getStreamsFairness() = %v, want %v", got, want)
+	}
+
+	status := &v1alpha1.NetperfStatus{}
+	cr := &v1alpha1.Netperf{Spec: v1alpha1.NetperfSpec{Direction: v1alpha1.NetperfDirectionBidirectional}}
+	n.setThroughputResults(cr, status, 0, streams)
+	if status.Fairness != nil || !reflect.DeepEqual(status.ClientToServerFairness, want) ||
+		status.ServerToClientFairness == nil || status.ServerToClientFairness.JainIndex != 1 {
+		t.Errorf("Netperf.setThroughputResults() fairness = %v, %v, %v, want only per direction", status.Fairness,
+			status.ClientToServerFairness, status.ServerToClientFairness)
+	}
+}
+
+func TestNetperf_getMatrixPairs(t *testing.T) {
+	tests := []struct {
+		name     string
+		nodes    []string
+		maxPairs int
+		want     int
+	}{
+		{name: "Single node", nodes: []string{"a"}, want: 0},
+		{name: "All pairs", nodes: []string{"a", "b", "c"}, want: 6},
+		{name: "Sampled pairs", nodes: []string{"a", "b", "c"}, maxPairs: 4, want: 4},
+		{name: "Sample bigger than all pairs", nodes: []string{"a", "b"}, maxPairs: 4, want: 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			n := &Netperf{
+				provider: fakekube.NewFakeProvider(),
+			}
+			got := n.getMatrixPairs(tt.nodes, tt.maxPairs, rand.New(rand.NewSource(1)))
+			if len(got) != tt.want {
+				t.Errorf("Netperf.getMatrixPairs() returned %d pairs, want %d", len(got), tt.want)
+			}
+			seen := map[string]bool{}
+			for _, p := range got {
+				key := p.ServerNode + "->" + p.ClientNode
+				if p.ServerNode == p.ClientNode || seen[key] {
+					t.Errorf("Netperf.getMatrixPairs() returned invalid or duplicate pair %s", key)
+				}
+				seen[key] = true
+			}
+		})
+	}
+}
+
+func TestNetperf_getScheduleTick(t *testing.T) {
+	created := time.Date(2018, time.June, 13, 10, 30, 0, 0, time.UTC)
+	lastTick := metav1.NewTime(time.Date(2018, time.June, 14, 2, 0, 0, 0, time.UTC))
+	hour := int64(3600)
+	tests := []struct {
+		name     string
+		last     *metav1.Time
+		deadline *int64
+		now      time.Time
+		want     time.Time
+		wantDue  bool
+		wantErr  bool
+	}{
+		{
+			name: "Not due yet",
+			now:  time.Date(2018, time.June, 14, 1, 59, 0, 0, time.UTC),
+		},
+		{
+			name:    "First tick",
+			now:     time.Date(2018, time.June, 14, 2, 0, 5, 0, time.UTC),
+			want:    time.Date(2018, time.June, 14, 2, 0, 0, 0, time.UTC),
+			wantDue: true,
+		},
+		{
+			name: "Tick already handled",
+			last: &lastTick,
+			now:  time.Date(2018, time.June, 14, 2, 0, 10, 0, time.UTC),
+		},
+		{
+			name:    "Missed ticks run once",
+			last:    &lastTick,
+			now:     time.Date(2018, time.June, 17, 3, 0, 0, 0, time.UTC),
+			want:    time.Date(2018, time.June, 17, 2, 0, 0, 0, time.UTC),
+			wantDue: true,
+		},
+		{
+			name:     "Tick within the starting deadline",
+			last:     &lastTick,
+			deadline: &hour,
+			now:      time.Date(2018, time.June, 17, 2, 30, 0, 0, time.UTC),
+			want:     time.Date(2018, time.June, 17, 2, 0, 0, 0, time.UTC),
+			wantDue:  true,
+		},
+		{
+			name:     "Tick missed the starting deadline",
+			last:     &lastTick,
+			deadline: &hour,
+			now:      time.Date(2018, time.June, 17, 3, 30, 0, 0, time.UTC),
+		},
+		{
+			name:    "Too many missed ticks",
+			last:    &lastTick,
+			now:     time.Date(2019, time.June, 17, 3, 0, 0, 0, time.UTC),
+			wantErr: true,
+		},
+		{
+			name:     "Many missed ticks with the starting deadline",
+			last:     &lastTick,
+			deadline: &hour,
+			now:      time.Date(2019, time.June, 17, 2, 30, 0, 0, time.UTC),
+			want:     time.Date(2019, time.June, 17, 2, 0, 0, 0, time.UTC),
+			wantDue:  true,
+		},
+	}
+	schedule, err := cron.Parse("0 2 * * *")
+	if err != nil {
+		t.Fatalf("cron.Parse() error = %v", err)
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			n := &Netperf{
+				provider: fakekube.NewFakeProvider(),
+			}
+			cr := &v1alpha1.NetperfSchedule{
+				ObjectMeta: metav1.ObjectMeta{CreationTimestamp: metav1.NewTime(created)},
+				Spec:       v1alpha1.NetperfScheduleSpec{StartingDeadlineSeconds: tt.deadline},
+				Status:     v1alpha1.NetperfScheduleStatus{LastScheduleTime: tt.last},
+			}
+			got, due, err := n.getScheduleTick(schedule, cr, tt.now)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Netperf.getScheduleTick() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if due != tt.wantDue || !got.Equal(tt.want) {
+				t.Errorf("Netperf.getScheduleTick() = %v, %v, want %v, %v", got, due, tt.want, tt.wantDue)
+			}
+		})
+	}
+}
+
+func TestNetperf_reconcileNetperfSchedule_invalid(t *testing.T) {
+	recorder := fakekube.NewFakeRecorder()
+	provider := &fakekube.FakeProvider{}
+	n := &Netperf{
+		provider: provider,
+		recorder: recorder,
+	}
+	cr := &v1alpha1.NetperfSchedule{Spec: v1alpha1.NetperfScheduleSpec{Schedule: "every night"}}
+	if err := n.reconcileNetperfSchedule(cr, time.Now()); err != nil {
+		t.Fatalf("Netperf.reconcileNetperfSchedule() error = %v", err)
+	}
+	if len(recorder.Events) != 1 || !strings.HasPrefix(recorder.Events[0], "Warning InvalidSpec") {
+		t.Errorf("Netperf.reconcileNetperfSchedule() recorded %v, want an InvalidSpec warning", recorder.Events)
+	}
+	if len(provider.Updated) != 1 || provider.Updated[0].(*v1alpha1.NetperfSchedule).Status.Message == "" {
+		t.Errorf("Netperf.reconcileNetperfSchedule() updated %v, want the status message set", provider.Updated)
+	}
+}
+
+func TestNetperf_finishRun(t *testing.T) {
+	n := &Netperf{
+		provider: fakekube.NewFakeProvider(),
+	}
+	cr := &v1alpha1.Netperf{Spec: v1alpha1.NetperfSpec{HistoryLimit: 2}}
+	for run := 0; run < 3; run++ {
+		cr.Status.Run = run
+		cr.Status.SpeedBitsPerSec = float64(1000 * run)
+		n.finishRun(cr, v1alpha1.NetperfPhaseDone)
+	}
+	if len(cr.Status.History) != 2 {
+		t.Fatalf("Netperf.finishRun() kept %d results, want 2", len(cr.Status.History))
+	}
+	if cr.Status.History[0].Run != 1 || cr.Status.History[1].SpeedBitsPerSec != 2000 {
+		t.Errorf("Netperf.finishRun() kept wrong results: %v", cr.Status.History)
+	}
+	if cr.Status.Status != v1alpha1.NetperfPhaseDone || cr.Status.CompletionTime == nil {
+		t.Errorf("Netperf.finishRun() didn't finish the run: %v", cr.Status)
+	}
+}
+
+func TestNetperf_truncateOutput(t *testing.T) {
+	n := &Netperf{
+		provider: fakekube.NewFakeProvider(),
+	}
+	if got := n.truncateOutput("short output"); got != "short output" {
+		t.Errorf("Netperf.truncateOutput() = %v, want unchanged output", got)
+	}
+	long := strings.Repeat("x", maxOutputBytes) + "result"
+	got := n.truncateOutput(long)
+	if len(got) != maxOutputBytes || !strings.HasPrefix(got, truncatedMarker) || !strings.HasSuffix(got, "result") {
+		t.Errorf("Netperf.truncateOutput() returned %d bytes, want %d with the end of the output", len(got), maxOutputBytes)
+	}
+	multiByte := n.truncateOutput(strings.Repeat("é", maxOutputBytes) + "xy")
+	if !utf8.ValidString(multiByte) || len(multiByte) > maxOutputBytes || !strings.HasSuffix(multiByte, "éxy") {
+		t.Errorf("Netperf.truncateOutput() returned %d bytes, valid UTF-8 %v, want at most %d bytes of valid UTF-8",
+			len(multiByte), utf8.ValidString(multiByte), maxOutputBytes)
+	}
+}
+
+func TestNetperf_shouldCleanup(t *testing.T) {
+	n := &Netperf{
+		provider: fakekube.NewFakeProvider(),
+	}
+	tests := []struct {
+		policy string
+		status string
+		want   bool
+	}{
+		{"", v1alpha1.NetperfPhaseDone, true},
+		{"", v1alpha1.NetperfPhaseError, false},
+		{v1alpha1.NetperfCleanupAlways, v1alpha1.NetperfPhaseError, true},
+		{v1alpha1.NetperfCleanupNever, v1alpha1.NetperfPhaseDone, false},
+	}
+	for _, tt := range tests {
+		cr := &v1alpha1.Netperf{Spec: v1alpha1.NetperfSpec{CleanupPolicy: tt.policy}}
+		if got := n.shouldCleanup(cr, tt.status); got != tt.want {
+			t.Errorf("Netperf.shouldCleanup(%q, %q) = %v, want %v", tt.policy, tt.status, got, tt.want)
+		}
+	}
+}
+
+func TestNetperf_isNetperfExpired(t *testing.T) {
+	day, hour := int32(86400), int32(3600)
+	n := &Netperf{
+		provider:   fakekube.NewFakeProvider(),
+		defaultTTL: &day,
+	}
+	finished := metav1.NewTime(time.Date(2018, 5, 1, 12, 0, 0, 0, time.UTC))
+	now := finished.Add(2 * time.Hour)
+	tests := []struct {
+		name   string
+		status string
+		ttl    *int32
+		owner  string
+		want   bool
+	}{
+		{"default TTL", v1alpha1.NetperfPhaseDone, nil, "", false},
+		{"own TTL", v1alpha1.NetperfPhaseError, &hour, "", true},
+		{"running", v1alpha1.NetperfPhaseTest, &hour, "", false},
+		{"matrix owner", v1alpha1.NetperfPhaseDone, &hour, "NetperfMatrix", false},
+		{"schedule owner", v1alpha1.NetperfPhaseDone, &hour, "NetperfSchedule", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cr := &v1alpha1.Netperf{
+				Spec:   v1alpha1.NetperfSpec{TTLSecondsAfterFinished: tt.ttl},
+				Status: v1alpha1.NetperfStatus{Status: tt.status, CompletionTime: &finished},
+			}
+			if tt.owner != "" {
+				cr.OwnerReferences = []metav1.OwnerReference{{Kind: tt.owner, Name: "owner"}}
+			}
+			if got := n.isNetperfExpired(cr, now); got != tt.want {
+				t.Errorf("Netperf.isNetperfExpired() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNetperf_checkTimeout(t *testing.T) {
+	recorder := fakekube.NewFakeRecorder()
+	provider := &fakekube.FakeProvider{}
+	n := &Netperf{
+		provider: provider,
+		recorder: recorder,
+	}
+	started := metav1.NewTime(time.Now().Add(-time.Minute))
+	cr := &v1alpha1.Netperf{
+		ObjectMeta: metav1.ObjectMeta{Name: "example", Namespace: "tests", UID: "6d5e3c1a-1b2c-4d5e-8f90-0123456789ab"},
+		Spec:       v1alpha1.NetperfSpec{TimeoutSeconds: 300},
+		Status: v1alpha1.NetperfStatus{Status: v1alpha1.NetperfPhaseTest, StartTime: &started,
+			ServerPod: "netperf-server-0123456789ab", ClientPod: "netperf-client-0123456789ab"},
+	}
+	if timedOut, _ := n.checkTimeout(cr); timedOut || len(recorder.Events) != 0 {
+		t.Errorf("Netperf.checkTimeout() = %v, want no timeout after a minute", timedOut)
+	}
+	cr.Spec.TimeoutSeconds = 30
+	if timedOut, _ := n.checkTimeout(cr); !timedOut {
+		t.Errorf("Netperf.checkTimeout() = %v, want timeout", timedOut)
+	}
+	if len(recorder.Events) != 1 || !strings.HasPrefix(recorder.Events[0], "Warning Timeout") {
+		t.Errorf("Netperf.checkTimeout() recorded %v, want a Timeout warning", recorder.Events)
+	}
+	var output string
+	for _, object := range provider.Updated {
+		if netperf, ok := object.(*v1alpha1.Netperf); ok {
+			output = netperf.Status.Output
+		}
+	}
+	if output != n.getOutputConfigMapName(cr) {
+		t.Errorf("Netperf.checkTimeout() saved output to %q, want %q", output, n.getOutputConfigMapName(cr))
+	}
+}
+
+func TestNetperf_setVerdict(t *testing.T) {
+	output := "stream 1: THROUGHPUT=9000.50\nP99_LATENCY=120\nLOCAL_TRANSPORT_RETRANS=3\nREMOTE_TRANSPORT_RETRANS=-1\n" +
+		"LOCAL_CPU_UTIL=12.5\nREMOTE_CPU_UTIL=40.1\n" +
+		"stream 2: THROUGHPUT=8000.25\nP99_LATENCY=250\nLOCAL_TRANSPORT_RETRANS=4\nREMOTE_TRANSPORT_RETRANS=-1\n" +
+		"LOCAL_CPU_UTIL=13.5\nREMOTE_CPU_UTIL=41.1\n"
+	maxRetransmits := int64(5)
+	tests := []struct {
+		name         string
+		expectations v1alpha1.NetperfExpectations
+		want         string
+	}{
+		{
+			name:         "Throughput met",
+			expectations: v1alpha1.NetperfExpectations{MinThroughputBitsPerSec: 17000},
+			want:         v1alpha1.NetperfVerdictPass,
+		},
+		{
+			name:         "Latency met",
+			expectations: v1alpha1.NetperfExpectations{MaxP99LatencyMicroseconds: 300, MaxCPUPercent: 50},
+			want:         v1alpha1.NetperfVerdictPass,
+		},
+		{
+			name:         "Too many retransmits",
+			expectations: v1alpha1.NetperfExpectations{MaxRetransmits: &maxRetransmits},
+			want:         v1alpha1.NetperfVerdictFail,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			n := &Netperf{
+				provider: fakekube.NewFakeProvider(),
+				recorder: fakekube.NewFakeRecorder(),
+			}
+			cr := &v1alpha1.Netperf{Spec: v1alpha1.NetperfSpec{ParallelStreams: 2, Expectations: &tt.expectations}}
+			streams, err := n.parseParallelNetperfResult(output, n.getNetperfStreams(cr))
+			if err != nil {
+				t.Fatalf("Netperf.parseParallelNetperfResult() error = %v", err)
+			}
+			status := &v1alpha1.NetperfStatus{Status: v1alpha1.NetperfPhaseDone}
+			n.setThroughputResults(cr, status, 0, streams)
+			if status.Stats, err = n.parseNetperfStats(output); err != nil {
+				t.Fatalf("Netperf.parseNetperfStats() error = %v", err)
+			}
+			n.setVerdict(cr, status)
+			if status.Verdict != tt.want || len(status.Conditions) != 1 {
+				t.Errorf("Netperf.setVerdict() = %v, conditions %v, want %v", status.Verdict, status.Conditions, tt.want)
+			}
+		})
+	}
+}
+
+func TestNetperf_checkRegression(t *testing.T) {
+	history := []v1alpha1.NetperfRunResult{
+		{Status: v1alpha1.NetperfPhaseDone, SpeedBitsPerSec: 2000},
+		{Status: v1alpha1.NetperfPhaseDone, SpeedBitsPerSec: 1100},
+		{Status: v1alpha1.NetperfPhaseError},
+		{Status: v1alpha1.NetperfPhaseDone, SpeedBitsPerSec: 900},
+	}
+	tests := []struct {
+		name       string
+		speed      float64
+		maxDrop    float64
+		wantChange float64
+		want       bool
+	}{
+		{"Within default deviation", 950, 0, -5, false},
+		{"Dropped", 800, 0, -20, true},
+		{"Dropped within allowed deviation", 800, 25, -20, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			n := &Netperf{
+				provider: fakekube.NewFakeProvider(),
+				recorder: fakekube.NewFakeRecorder(),
+			}
+			cr := &v1alpha1.Netperf{
+				Spec: v1alpha1.NetperfSpec{
+					Baseline: &v1alpha1.NetperfBaseline{HistoryRuns: 2, MaxThroughputDropPercent: tt.maxDrop},
+				},
+				Status: v1alpha1.NetperfStatus{History: history},
+			}
+			status := &v1alpha1.NetperfStatus{SpeedBitsPerSec: tt.speed}
+			n.checkRegression(cr, status)
+			if status.Regression == nil {
+				t.Fatalf("Netperf.checkRegression() didn't compare with the baseline")
+			}
+			if status.Regression.Regressed != tt.want || status.Regression.BaselineSpeedBitsPerSec != 1000 ||
+				status.Regression.ThroughputChangePercent != tt.wantChange {
+				t.Errorf("Netperf.checkRegression() = %+v, want regressed %v, change %v", *status.Regression, tt.want,
+					tt.wantChange)
+			}
+		})
+	}
+}
+
+func TestIperf3Backend_parseResult(t *testing.T) {
+	tcp := `{"intervals": [{"sum": {"start": 0, "end": 1, "bits_per_second": 1e9, "omitted": true}},
+		{"sum": {"start": 0, "end": 1, "bits_per_second": 9.2e8}}, {"sum": {"start": 1, "end": 2, "bits_per_second": 9.6e8}}],
+		"end": {"streams": [{"sender": {"bits_per_second": 9.5e8, "retransmits": 3, "sender": true},
+		"receiver": {"bits_per_second": 9.4e8, "sender": true}}],
+		"sum_sent": {"bits_per_second": 9.5e8, "retransmits": 3},
+		"cpu_utilization_percent": {"host_total": 12.5, "remote_total": 30}}}`
+	bidir := `{"intervals": [{"sum": {"start": 0, "end": 1, "bits_per_second": 4e8},
+		"sum_bidir_reverse": {"start": 0, "end": 1, "bits_per_second": 2e8}}], "end": {"streams": [
+		{"sender": {"bits_per_second": 5e8, "sender": true}, "receiver": {"bits_per_second": 4e8, "sender": true}},
+		{"sender": {"bits_per_second": 3e8, "sender": false}, "receiver": {"bits_per_second": 2e8, "sender": false}}],
+		"sum_sent": {"retransmits": 1}, "sum_sent_bidir_reverse": {"retransmits": 2}}}`
+	udp := `{"end": {"streams": [{"udp": {"bits_per_second": 1e6, "lost_percent": 10, "sender": true}}],
+		"sum": {"bits_per_second": 1e6, "jitter_ms": 0.5, "lost_packets": 9, "packets": 90, "lost_percent": 10}}}`
+	tests := []struct {
+		name        string
+		spec        v1alpha1.NetperfSpec
+		output      string
+		want        float64
+		wantReverse float64
+		wantRetrans int64
+		// wantIntervals has the number of intervals, min and max throughput
+		wantIntervals []float64
+		wantErr       bool
+	}{
+		{"TCP", v1alpha1.NetperfSpec{}, tcp, 940, 0, 3, []float64{2, 920, 960}, false},
+		{"Bidirectional", v1alpha1.NetperfSpec{Direction: v1alpha1.NetperfDirectionBidirectional}, bidir, 600, 200, 3,
+			[]float64{1, 600, 600}, false},
+		{"UDP", v1alpha1.NetperfSpec{Iperf3: &v1alpha1.NetperfIperf3Spec{UDP: true}}, udp, 0.9, 0, 0, nil, false},
+		{"Error", v1alpha1.NetperfSpec{}, `{"error": "unable to connect to server"}`, 0, 0, 0, nil, true},
+		{"Not JSON", v1alpha1.NetperfSpec{}, "iperf3: error", 0, 0, 0, nil, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b := &iperf3Backend{n: &Netperf{}}
+			cr := &v1alpha1.Netperf{Spec: tt.spec}
+			status := &v1alpha1.NetperfStatus{}
+			err := b.parseResult(cr, tt.output, status)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("iperf3Backend.parseResult() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			if status.SpeedBitsPerSec != tt.want || status.ServerToClientBitsPerSec != tt.wantReverse ||
+				status.Stats.Retransmits != tt.wantRetrans {
+				t.Errorf("iperf3Backend.parseResult() = %v, %v, %d retransmits, want %v, %v, %d", status.SpeedBitsPerSec,
+					status.ServerToClientBitsPerSec, status.Stats.Retransmits, tt.want, tt.wantReverse, tt.wantRetrans)
+			}
+			var intervals []float64
+			if status.Intervals != nil {
+				intervals = []float64{float64(len(status.Intervals.Series)), status.Intervals.MinBitsPerSec,
+					status.Intervals.MaxBitsPerSec}
+			}
+			if !reflect.DeepEqual(intervals, tt.wantIntervals) {
+				t.Errorf("iperf3Backend.parseResult() intervals = %v, want %v", intervals, tt.wantIntervals)
+			}
+			if tt.spec.Iperf3 != nil && tt.spec.Iperf3.UDP && (status.UDP == nil || status.UDP.LostPackets != 9) {
+				t.Errorf("iperf3Backend.parseResult() UDP = %+v, want 9 lost packets", status.UDP)
+			}
+		})
+	}
+}
+
+func TestLatencyBackend_parseResult(t *testing.T) {
+	ping := `PING 10.0.0.2 (10.0.0.2) 56(84) bytes of data.
+64 bytes from 10.0.0.2: icmp_seq=1 ttl=64 time=0.100 ms
+64 bytes from 10.0.0.2: icmp_seq=2 ttl=64 time=0.300 ms
+64 bytes from 10.0.0.2: icmp_seq=4 ttl=64 time=0.200 ms
+
+--- 10.0.0.2 ping statistics ---
+4 packets transmitted, 3 received, 25% packet loss, time 3004ms`
+	tcp := "connect=0.000100\nconnect=0.000000\nconnect=0.000300\nconnect=0.000200\n"
+	sockperf := `sockperf: [Valid Duration] RunTime=1.000 sec; SentMessages=1000; ReceivedMessages=990
+sockperf: ====> avg-rtt=44.678 (std-dev=4.376)
+sockperf: ---> <MAX> observation =  120.500
+sockperf: ---> percentile 99.999 =  120.500
+sockperf: ---> percentile 99.000 =   60.250
+sockperf: ---> percentile 50.000 =   43.100
+sockperf: ---> <MIN> observation =   38.000`
+	tests := []struct {
+		name    string
+		mode    string
+		output  string
+		want    v1alpha1.NetperfLatencyResult
+		wantErr bool
+	}{
+		{"Ping", v1alpha1.NetperfLatencyModeICMP, ping, v1alpha1.NetperfLatencyResult{MinMicroseconds: 100,
+			AvgMicroseconds: 200, P99Microseconds: 300, MaxMicroseconds: 300, JitterMicroseconds: 150,
+			StdDevMicroseconds: 81.65, Sent: 4,
+			Received: 3, LossPercent: 25}, false},
+		{"TCP connect", v1alpha1.NetperfLatencyModeTCP, tcp, v1alpha1.NetperfLatencyResult{MinMicroseconds: 100,
+			AvgMicroseconds: 200, P99Microseconds: 300, MaxMicroseconds: 300, JitterMicroseconds: 150,
+			StdDevMicroseconds: 81.65, Sent: 4,
+			Received: 3, LossPercent: 25}, false},
+		{"Sockperf", v1alpha1.NetperfLatencyModeSockperf, sockperf, v1alpha1.NetperfLatencyResult{MinMicroseconds: 38,
+			AvgMicroseconds: 44.678, P99Microseconds: 60.25, MaxMicroseconds: 120.5, StdDevMicroseconds: 4.376,
+			Sent: 1000, Received: 990, LossPercent: 1}, false},
+		{"Unreachable", v1alpha1.NetperfLatencyModeICMP, "4 packets transmitted, 0 received, 100% packet loss",
+			v1alpha1.NetperfLatencyResult{}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b := &latencyBackend{n: &Netperf{}}
+			cr := &v1alpha1.Netperf{Spec: v1alpha1.NetperfSpec{Latency: &v1alpha1.NetperfLatencySpec{Mode: tt.mode, Count: 4}}}
+			status := &v1alpha1.NetperfStatus{}
+			err := b.parseResult(cr, tt.output, status)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("latencyBackend.parseResult() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			got := *status.Latency
+			for _, v := range []*float64{&got.MinMicroseconds, &got.AvgMicroseconds, &got.P99Microseconds,
+				&got.MaxMicroseconds, &got.JitterMicroseconds, &got.StdDevMicroseconds, &got.LossPercent} {
+				*v = math.Floor(*v*1000+0.5) / 1000
+			}
+			if got != tt.want || status.Stats.P99LatencyMicroseconds != tt.want.P99Microseconds {
+				t.Errorf("latencyBackend.parseResult() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestHTTPBackend_parseResult(t *testing.T) {
+	output := `Fortio 1.3.1 running at 100 queries per second, 2->2 procs, for 10s: http://10.0.0.2:8080/
+Ended after 10.001s : 1000 calls. qps=99.99
+{
+  "RunType": "HTTP",
+  "ActualQPS": 99.99,
+  "DurationHistogram": {
+    "Count": 1000,
+    "Max": 0.012,
+    "Avg": 0.0015,
+    "Percentiles": [
+      {"Percentile": 50, "Value": 0.001},
+      {"Percentile": 90, "Value": 0.002},
+      {"Percentile": 99, "Value": 0.004}
+    ]
+  },
+  "RetCodes": {"200": 990, "503": 10}
+}`
+	tests := []struct {
+		name    string
+		output  string
+		want    v1alpha1.NetperfHTTPResult
+		wantErr bool
+	}{
+		{"Results", output, v1alpha1.NetperfHTTPResult{TargetQPS: 100, ActualQPS: 99.99, Requests: 1000, Errors: 10,
+			ErrorPercent: 1, AvgMicroseconds: 1500, P50Microseconds: 1000, P90Microseconds: 2000, P99Microseconds: 4000,
+			MaxMicroseconds: 12000}, false},
+		{"Only results", output[strings.Index(output, "{"):], v1alpha1.NetperfHTTPResult{TargetQPS: 100,
+			ActualQPS: 99.99, Requests: 1000, Errors: 10, ErrorPercent: 1, AvgMicroseconds: 1500, P50Microseconds: 1000,
+			P90Microseconds: 2000, P99Microseconds: 4000, MaxMicroseconds: 12000}, false},
+		{"No results", "Aborting because of error", v1alpha1.NetperfHTTPResult{}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b := &httpBackend{n: &Netperf{}}
+			status := &v1alpha1.NetperfStatus{}
+			err := b.parseResult(&v1alpha1.Netperf{}, tt.output, status)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("httpBackend.parseResult() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			got := *status.HTTP
+			for _, v := range []*float64{&got.AvgMicroseconds, &got.P50Microseconds, &got.P90Microseconds,
+				&got.P99Microseconds, &got.MaxMicroseconds} {
+				*v = math.Floor(*v + 0.5)
+			}
+			if got != tt.want {
+				t.Errorf("httpBackend.parseResult() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDNSBackend_parseResult(t *testing.T) {
+	tests := []struct {
+		name    string
+		output  string
+		want    v1alpha1.NetperfDNSResult
+		wantErr bool
+	}{
+		{"Queries", "ok=0.000200\nok=0.000100\nfailed=5.000000\nok=0.000400\nelapsed=5.300000\n",
+			v1alpha1.NetperfDNSResult{TargetQPS: 10, ActualQPS: 0.755, Queries: 4, Failures: 1, FailurePercent: 25,
+				MinMicroseconds: 100, AvgMicroseconds: 233, P50Microseconds: 200, P99Microseconds: 400,
+				MaxMicroseconds: 400}, false},
+		{"High latency", "ok=0.500000\nok=0.500000\nok=0.500000\nok=0.500000\nelapsed=2.000000\n",
+			v1alpha1.NetperfDNSResult{TargetQPS: 10, ActualQPS: 2, Queries: 4, MinMicroseconds: 500000,
+				AvgMicroseconds: 500000, P50Microseconds: 500000, P99Microseconds: 500000, MaxMicroseconds: 500000},
+			false},
+		{"All failed", "failed=5.000000\n", v1alpha1.NetperfDNSResult{TargetQPS: 10, Queries: 1, Failures: 1,
+			FailurePercent: 100}, false},
+		{"No queries", "python3: not found", v1alpha1.NetperfDNSResult{}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b := &dnsBackend{n: &Netperf{}}
+			status := &v1alpha1.NetperfStatus{}
+			err := b.parseResult(&v1alpha1.Netperf{}, tt.output, status)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("dnsBackend.parseResult() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			got := *status.DNS
+			for _, v := range []*float64{&got.MinMicroseconds, &got.AvgMicroseconds, &got.P50Microseconds,
+				&got.P99Microseconds, &got.MaxMicroseconds} {
+				*v = math.Floor(*v)
+			}
+			got.ActualQPS = math.Floor(got.ActualQPS*1000+0.5) / 1000
+			if got != tt.want {
+				t.Errorf("dnsBackend.parseResult() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMTUBackend_parseResult(t *testing.T) {
+	tests := []struct {
+		name       string
+		output     string
+		want       v1alpha1.NetperfMTUResult
+		wantEvents int
+		wantErr    bool
+	}{
+		{"Matching", "client_mtu=1450\nserver_mtu=1450\nprobe=759 ok\npath_mtu=1450\nfragmentation=ok\n",
+			v1alpha1.NetperfMTUResult{PathMTU: 1450, ClientInterfaceMTU: 1450, ServerInterfaceMTU: 1450,
+				FragmentationWorks: true}, 0, false},
+		{"Mismatch", "client_mtu=1500\nserver_mtu=\npath_mtu=1450\nfragmentation=failed\n",
+			v1alpha1.NetperfMTUResult{PathMTU: 1450, ClientInterfaceMTU: 1500, Mismatch: true}, 1, false},
+		{"Unreachable", "client_mtu=1500\nserver_mtu=\nserver 10.0.0.2 is unreachable\n",
+			v1alpha1.NetperfMTUResult{}, 0, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			recorder := fakekube.NewFakeRecorder()
+			b := &mtuBackend{n: &Netperf{recorder: recorder}}
+			status := &v1alpha1.NetperfStatus{}
+			err := b.parseResult(&v1alpha1.Netperf{}, tt.output, status)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("mtuBackend.parseResult() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			if *status.MTU != tt.want || len(recorder.Events) != tt.wantEvents {
+				t.Errorf("mtuBackend.parseResult() = %+v, events %v, want %+v", *status.MTU, recorder.Events, tt.want)
+			}
+		})
+	}
+}
+
+func TestNetperf_isClientPodSucceeded(t *testing.T) {
+	terminated := func(code int32) v1.ContainerStatus {
+		return v1.ContainerStatus{Name: "client", State: v1.ContainerState{
+			Terminated: &v1.ContainerStateTerminated{ExitCode: code}}}
+	}
+	running := v1.ContainerStatus{Name: "istio-proxy", State: v1.ContainerState{Running: &v1.ContainerStateRunning{}}}
+	tests := []struct {
+		name     string
+		phase    v1.PodPhase
+		statuses []v1.ContainerStatus
+		want     bool
+	}{
+		{"Pod succeeded", v1.PodSucceeded, nil, true},
+		{"Test running", v1.PodRunning, []v1.ContainerStatus{{Name: "client"}, running}, false},
+		{"Sidecar still running", v1.PodRunning, []v1.ContainerStatus{terminated(0), running}, true},
+		{"Test failed", v1.PodRunning, []v1.ContainerStatus{terminated(1), running}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			n := &Netperf{}
+			pod := &v1.Pod{
+				ObjectMeta: metav1.ObjectMeta{Name: "client"},
+				Status:     v1.PodStatus{Phase: tt.phase, ContainerStatuses: tt.statuses},
+			}
+			if got := n.isClientPodSucceeded(pod); got != tt.want {
+				t.Errorf("Netperf.isClientPodSucceeded() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNetperf_setSidecarMetadata(t *testing.T) {
+	tests := []struct {
+		name     string
+		sidecar  *v1alpha1.NetperfSidecarSpec
+		injected bool
+		want     map[string]string
+	}{
+		{"No sidecar", nil, false, map[string]string{}},
+		{"Default", &v1alpha1.NetperfSidecarSpec{}, false, map[string]string{sidecarInjectLabel: "true"}},
+		{"Compare without sidecar", &v1alpha1.NetperfSidecarSpec{Compare: true}, false,
+			map[string]string{sidecarInjectLabel: "false"}},
+		{"Compare with sidecar", &v1alpha1.NetperfSidecarSpec{Compare: true}, true,
+			map[string]string{sidecarInjectLabel: "true"}},
+		{"Custom", &v1alpha1.NetperfSidecarSpec{WithSidecar: v1alpha1.NetperfPodMetadata{
+			Labels: map[string]string{"mesh": "on"}}}, false, map[string]string{"mesh": "on"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			n := &Netperf{}
+			cr := &v1alpha1.Netperf{
+				Spec:   v1alpha1.NetperfSpec{Sidecar: tt.sidecar},
+				Status: v1alpha1.NetperfStatus{SidecarInjected: tt.injected},
+			}
+			meta := &metav1.ObjectMeta{Labels: map[string]string{}}
+			n.setSidecarMetadata(cr, meta)
+			if !reflect.DeepEqual(meta.Labels, tt.want) {
+				t.Errorf("Netperf.setSidecarMetadata() = %v, want %v", meta.Labels, tt.want)
+			}
+		})
+	}
+}
+
+func TestNetperf_getNetperfReference(t *testing.T) {
+	tests := []struct {
+		name          string
+		meta          metav1.ObjectMeta
+		wantNamespace string
+		wantName      string
+		wantFound     bool
+	}{
+		{"Owner", metav1.ObjectMeta{Namespace: "tests", OwnerReferences: []metav1.OwnerReference{
+			{Kind: "Netperf", Name: "example", UID: "uid"}}}, "tests", "example", true},
+		{"Annotation", metav1.ObjectMeta{Namespace: "servers",
+			Labels:      map[string]string{"app": "netperf-operator", "netperf-id": "0123456789ab"},
+			Annotations: map[string]string{netperfAnnotation: "tests/example"}}, "tests", "example", true},
+		{"Annotation without labels", metav1.ObjectMeta{Namespace: "servers",
+			Annotations: map[string]string{netperfAnnotation: "tests/example"}}, "", "", false},
+		{"Other owner", metav1.ObjectMeta{Namespace: "tests", OwnerReferences: []metav1.OwnerReference{
+			{Kind: "ReplicaSet", Name: "example"}}}, "", "", false},
+		{"No owner", metav1.ObjectMeta{Namespace: "tests"}, "", "", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			n := &Netperf{}
+			namespace, name, found := n.getNetperfReference(&v1.Pod{ObjectMeta: tt.meta})
+			if namespace != tt.wantNamespace || name != tt.wantName || found != tt.wantFound {
+				t.Errorf("Netperf.getNetperfReference() = %v, %v, %v, want %v, %v, %v", namespace, name, found,
+					tt.wantNamespace, tt.wantName, tt.wantFound)
+			}
+		})
+	}
+}
+
+func TestNetperf_newNetperfPod_crossNamespace(t *testing.T) {
+	n := &Netperf{}
+	cr := &v1alpha1.Netperf{
+		ObjectMeta: metav1.ObjectMeta{Name: "example", Namespace: "tests", UID: "6d5e3c1a-1b2c-4d5e-8f90-0123456789ab"},
+		Spec:       v1alpha1.NetperfSpec{ServerNamespace: "servers"},
+	}
+	server := n.newNetperfPod(cr, netperfTypeServer, v1.RestartPolicyAlways, nil)
+	if server.Namespace != "servers" || len(server.OwnerReferences) != 0 ||
+		server.Annotations[netperfAnnotation] != "tests/example" {
+		t.Errorf("Netperf.newNetperfPod() server = %s/%s, owners %v, annotations %v", server.Namespace, server.Name,
+			server.OwnerReferences, server.Annotations)
+	}
+	client := n.newNetperfPod(cr, netperfTypeClient, v1.RestartPolicyOnFailure, nil)
+	if client.Namespace != "tests" || len(client.OwnerReferences) != 1 {
+		t.Errorf("Netperf.newNetperfPod() client = %s/%s, owners %v", client.Namespace, client.Name, client.OwnerReferences)
+	}
+	policy := n.newNetworkPolicy(cr, netperfTypeServer)
+	peer := policy.Spec.Ingress[0].From[0]
+	if policy.Namespace != "servers" || peer.NamespaceSelector == nil ||
+		peer.NamespaceSelector.MatchLabels[namespaceNameLabel] != "tests" {
+		t.Errorf("Netperf.newNetworkPolicy() = %s/%s, peer %+v", policy.Namespace, policy.Name, peer)
+	}
+}
+
+func TestNetperf_deleteNetperfPods_crossNamespace(t *testing.T) {
+	provider := &fakekube.FakeProvider{}
+	n := &Netperf{provider: provider}
+	cr := &v1alpha1.Netperf{
+		ObjectMeta: metav1.ObjectMeta{Name: "example", Namespace: "tests", UID: "6d5e3c1a-1b2c-4d5e-8f90-0123456789ab"},
+		Spec: v1alpha1.NetperfSpec{
+			ServerNamespace: "servers",
+			Backend:         v1alpha1.NetperfBackendHTTP,
+			HTTP:            &v1alpha1.NetperfHTTPSpec{ViaService: true},
+		},
+		Status: v1alpha1.NetperfStatus{ServerPod: "netperf-server-123", ClientPod: "netperf-client-123"},
+	}
+	if err := n.deleteNetperfPods(cr); err != nil {
+		t.Fatalf("Netperf.deleteNetperfPods() error = %v", err)
+	}
+	var service *v1.Service
+	for _, object := range provider.Deleted {
+		if s, ok := object.(*v1.Service); ok {
+			service = s
+		}
+	}
+	if len(provider.Deleted) != 3 || service == nil || service.Namespace != "servers" ||
+		service.Name != n.getHTTPServiceName(cr) {
+		t.Errorf("Netperf.deleteNetperfPods() deleted %d objects, service %v, want both pods and the service",
+			len(provider.Deleted), service)
+	}
+}
+
+func TestNetperf_parseServerVersion(t *testing.T) {
+	tests := []struct {
+		major, minor string
+		wantMajor    int
+		wantMinor    int
+		wantOk       bool
+	}{
+		{"1", "21", 1, 21, true},
+		{"1", "10+", 1, 10, true},
+		{"1", "", 0, 0, false},
+		{"v1", "21", 0, 0, false},
+	}
+	n := &Netperf{}
+	for _, tt := range tests {
+		t.Run(tt.major+"."+tt.minor, func(t *testing.T) {
+			major, minor, ok := n.parseServerVersion(tt.major, tt.minor)
+			if major != tt.wantMajor || minor != tt.wantMinor || ok != tt.wantOk {
+				t.Errorf("Netperf.parseServerVersion() = %v, %v, %v, want %v, %v, %v", major, minor, ok,
+					tt.wantMajor, tt.wantMinor, tt.wantOk)
+			}
+		})
+	}
+}
+
+func TestNetperf_getResultSummary(t *testing.T) {
+	tests := []struct {
+		name    string
+		backend string
+		status  v1alpha1.NetperfStatus
+		want    string
+	}{
+		{"netperf", "", v1alpha1.NetperfStatus{SpeedBitsPerSec: 9000.5}, "throughput: 9000.50 Mbit/s"},
+		{"iperf3", v1alpha1.NetperfBackendIperf3, v1alpha1.NetperfStatus{SpeedBitsPerSec: 940}, "throughput: 940.00 Mbit/s"},
+		{
+			"latency", v1alpha1.NetperfBackendLatency,
+			v1alpha1.NetperfStatus{Stats: &v1alpha1.NetperfStats{P99LatencyMicroseconds: 120.5}},
+			"p99 latency: 120.50us",
+		},
+		{
+			"mtu", v1alpha1.NetperfBackendMTU,
+			v1alpha1.NetperfStatus{MTU: &v1alpha1.NetperfMTUResult{PathMTU: 1450}},
+			"path MTU: 1450",
+		},
+	}
+	n := &Netperf{}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cr := &v1alpha1.Netperf{Spec: v1alpha1.NetperfSpec{Backend: tt.backend}, Status: tt.status}
+			if got := n.getResultSummary(cr); got != tt.want {
+				t.Errorf("Netperf.getResultSummary() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNetperf_validateSpec(t *testing.T) {
+	tests := []struct {
+		name    string
+		spec    v1alpha1.NetperfSpec
+		wantErr bool
+	}{
+		{"netperf", v1alpha1.NetperfSpec{NetworkPolicy: v1alpha1.NetperfNetworkPolicyAllow}, false},
+		{"bidirectional", v1alpha1.NetperfSpec{Direction: v1alpha1.NetperfDirectionBidirectional}, false},
+		{"unknown direction", v1alpha1.NetperfSpec{Direction: "both"}, true},
+		{"unknown backend", v1alpha1.NetperfSpec{Backend: "qperf"}, true},
+		{"cleanup policy", v1alpha1.NetperfSpec{CleanupPolicy: v1alpha1.NetperfCleanupNever}, false},
+		{"unknown cleanup policy", v1alpha1.NetperfSpec{CleanupPolicy: "OnFailure"}, true},
+		{"unknown network policy", v1alpha1.NetperfSpec{NetworkPolicy: "Deny"}, true},
+		{"icmp", v1alpha1.NetperfSpec{Backend: v1alpha1.NetperfBackendLatency}, false},
+		{
+			"icmp with network policy",
+			v1alpha1.NetperfSpec{Backend: v1alpha1.NetperfBackendLatency, NetworkPolicy: v1alpha1.NetperfNetworkPolicyEnforced},
+			true,
+		},
+		{
+			"tcp with network policy",
+			v1alpha1.NetperfSpec{Backend: v1alpha1.NetperfBackendLatency, NetworkPolicy: v1alpha1.NetperfNetworkPolicyAllow,
+				Latency: &v1alpha1.NetperfLatencySpec{Mode: v1alpha1.NetperfLatencyModeTCP}},
+			false,
+		},
+	}
+	n := &Netperf{provider: fakekube.NewFakeProvider()}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cr := &v1alpha1.Netperf{ObjectMeta: metav1.ObjectMeta{Name: "example", Namespace: "default"}, Spec: tt.spec}
+			if err := n.validateSpec(cr); (err != nil) != tt.wantErr {
+				t.Errorf("Netperf.validateSpec() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestIperf3Backend_getIntervals(t *testing.T) {
+	result := &iperf3Output{}
+	for i := 0; i < 100; i++ {
+		sum := &iperf3IntervalSum{Start: float64(i), End: float64(i + 1), BitsPerSecond: float64(i+1) * 1e6}
+		result.Intervals = append(result.Intervals, iperf3Interval{Sum: sum})
+	}
+	intervals := (&iperf3Backend{n: &Netperf{}}).getIntervals(result)
+	if len(intervals.Series) != maxIperf3Intervals || intervals.MinBitsPerSec != 1 || intervals.MaxBitsPerSec != 100 {
+		t.Errorf("iperf3Backend.getIntervals() = %d intervals, min %v, max %v, want %d, 1, 100",
+			len(intervals.Series), intervals.MinBitsPerSec, intervals.MaxBitsPerSec, maxIperf3Intervals)
+	}
+}
+
+func TestNetperf_newNetworkPolicy(t *testing.T) {
+	tests := []struct {
+		name        string
+		spec        v1alpha1.NetperfSpec
+		npType      netperfType
+		wantPorts   []int
+		wantPeer    netperfType
+		wantDNSRule bool
+	}{
+		{"server", v1alpha1.NetperfSpec{}, netperfTypeServer, []int{12865, 12866}, netperfTypeClient, false},
+		{"client", v1alpha1.NetperfSpec{}, netperfTypeClient, []int{12865, 12866}, netperfTypeServer, false},
+		{
+			"server with parallel streams", v1alpha1.NetperfSpec{ParallelStreams: 3}, netperfTypeServer,
+			[]int{12865, 12866, 12867, 12868}, netperfTypeClient, false,
+		},
+		{
+			"client of bidirectional streams",
+			v1alpha1.NetperfSpec{ParallelStreams: 2, Direction: v1alpha1.NetperfDirectionBidirectional},
+			netperfTypeClient, []int{12865, 12866, 12867, 12868, 12869}, netperfTypeServer, false,
+		},
+		{
+			"client of HTTP service",
+			v1alpha1.NetperfSpec{Backend: v1alpha1.NetperfBackendHTTP, HTTP: &v1alpha1.NetperfHTTPSpec{ViaService: true}},
+			netperfTypeClient, []int{httpPort}, netperfTypeServer, true,
+		},
+	}
+	n := &Netperf{}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cr := &v1alpha1.Netperf{
+				ObjectMeta: metav1.ObjectMeta{Name: "example", Namespace: "default", UID: "6d5e3c1a-1b2c-4d5e-8f90-0123456789ab"},
+				Spec:       tt.spec,
+			}
+			policy := n.newNetworkPolicy(cr, tt.npType)
+			var ports []networkingv1.NetworkPolicyPort
+			var peers []networkingv1.NetworkPolicyPeer
+			rules := 0
+			if tt.npType == netperfTypeServer {
+				if len(policy.Spec.PolicyTypes) != 1 || policy.Spec.PolicyTypes[0] != networkingv1.PolicyTypeIngress {
+					t.Errorf("Netperf.newNetworkPolicy() policy types = %v, want Ingress", policy.Spec.PolicyTypes)
+				}
+				rules = len(policy.Spec.Ingress)
+				ports, peers = policy.Spec.Ingress[0].Ports, policy.Spec.Ingress[0].From
+			} else {
+				if len(policy.Spec.PolicyTypes) != 1 || policy.Spec.PolicyTypes[0] != networkingv1.PolicyTypeEgress {
+					t.Errorf("Netperf.newNetworkPolicy() policy types = %v, want Egress", policy.Spec.PolicyTypes)
+				}
+				rules = len(policy.Spec.Egress)
+				ports, peers = policy.Spec.Egress[0].Ports, policy.Spec.Egress[0].To
+			}
+			if !reflect.DeepEqual(policy.Spec.PodSelector, *n.getNetperfPodSelector(cr, tt.npType)) {
+				t.Errorf("Netperf.newNetworkPolicy() selects %v, want the %v pod", policy.Spec.PodSelector, tt.npType)
+			}
+			var gotPorts []int
+			for _, port := range ports {
+				gotPorts = append(gotPorts, port.Port.IntValue())
+			}
+			if !reflect.DeepEqual(gotPorts, tt.wantPorts) {
+				t.Errorf("Netperf.newNetworkPolicy() ports = %v, want %v", gotPorts, tt.wantPorts)
+			}
+			if len(peers) != 1 || !reflect.DeepEqual(peers[0], n.getNetperfPeer(cr, tt.wantPeer)) {
+				t.Errorf("Netperf.newNetworkPolicy() peers = %v, want the %v pod", peers, tt.wantPeer)
+			}
+			wantRules := 1
+			if tt.wantDNSRule {
+				wantRules = 2
+			}
+			if rules != wantRules {
+				t.Errorf("Netperf.newNetworkPolicy() has %d rules, DNS rule wanted: %v", rules, tt.wantDNSRule)
+			}
+		})
+	}
+}
+
+func TestNetperf_startEnforcedRun(t *testing.T) {
+	tests := []struct {
+		name             string
+		spec             v1alpha1.NetperfSpec
+		wantServerPolicy string
+		wantClientPolicy string
+	}{
+		{"same namespace", v1alpha1.NetperfSpec{NetworkPolicy: v1alpha1.NetperfNetworkPolicyEnforced}, "tests", "tests"},
+		{
+			"cross namespace",
+			v1alpha1.NetperfSpec{NetworkPolicy: v1alpha1.NetperfNetworkPolicyEnforced, ServerNamespace: "servers"},
+			"servers", "tests",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			provider := &fakekube.FakeProvider{}
+			n := &Netperf{provider: provider, recorder: fakekube.NewFakeRecorder()}
+			cr := &v1alpha1.Netperf{
+				ObjectMeta: metav1.ObjectMeta{Name: "example", Namespace: "tests", UID: "6d5e3c1a-1b2c-4d5e-8f90-0123456789ab"},
+				Spec:       tt.spec,
+				Status: v1alpha1.NetperfStatus{Status: v1alpha1.NetperfPhaseTest, ServerPod: "netperf-server-123",
+					ClientPod: "netperf-client-123"},
+			}
+			clientPod := &v1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "netperf-client-123", Namespace: "tests"}}
+			if err := n.startEnforcedRun(cr, clientPod, 9000.5); err != nil {
+				t.Fatalf("Netperf.startEnforcedRun() error = %v", err)
+			}
+			if len(provider.Deleted) != 1 || provider.Deleted[0] != clientPod {
+				t.Errorf("Netperf.startEnforcedRun() deleted %v, want the client pod", provider.Deleted)
+			}
+			namespaces := map[string]string{}
+			for _, object := range provider.Created {
+				if policy, ok := object.(*networkingv1.NetworkPolicy); ok {
+					namespaces[policy.Name] = policy.Namespace
+				}
+			}
+			wantNamespaces := map[string]string{
+				"netperf-server-0123456789ab": tt.wantServerPolicy,
+				"netperf-client-0123456789ab": tt.wantClientPolicy,
+			}
+			if !reflect.DeepEqual(namespaces, wantNamespaces) {
+				t.Errorf("Netperf.startEnforcedRun() created policies %v, want %v", namespaces, wantNamespaces)
+			}
+			if len(provider.Updated) != 1 {
+				t.Fatalf("Netperf.startEnforcedRun() updated %d objects, want 1", len(provider.Updated))
+			}
+			status := provider.Updated[0].(*v1alpha1.Netperf).Status
+			if status.UnenforcedSpeedBitsPerSec != 9000.5 || !status.PolicyEnforced || status.ClientPod != "" ||
+				status.ServerPod != "netperf-server-123" || status.Status != v1alpha1.NetperfPhaseServer {
+				t.Errorf("Netperf.startEnforcedRun() status = %+v, want the enforced run to start", status)
+			}
+			if cr.Status.PolicyEnforced {
+				t.Errorf("Netperf.startEnforcedRun() modified the cached object")
+			}
+		})
+	}
+}
+
+func TestNetperf_getTestPodType(t *testing.T) {
+	n := &Netperf{}
+	cr := &v1alpha1.Netperf{
+		ObjectMeta: metav1.ObjectMeta{Name: "example", Namespace: "tests", UID: "6d5e3c1a-1b2c-4d5e-8f90-0123456789ab"},
+		Spec:       v1alpha1.NetperfSpec{ServerNamespace: "servers"},
+		Status:     v1alpha1.NetperfStatus{ServerPod: "netperf-server-123", ClientPod: "netperf-client-123"},
+	}
+	labels := func(npType netperfType) map[string]string {
+		return map[string]string{"netperf-id": "0123456789ab", "netperf-type": fmt.Sprint(npType)}
+	}
+	tests := []struct {
+		name      string
+		pod       metav1.ObjectMeta
+		wantType  netperfType
+		wantFound bool
+	}{
+		{"server", metav1.ObjectMeta{Name: "netperf-server-123", Namespace: "servers", Labels: labels(netperfTypeServer)},
+			netperfTypeServer, true},
+		{"client", metav1.ObjectMeta{Name: "netperf-client-123", Namespace: "tests", Labels: labels(netperfTypeClient)},
+			netperfTypeClient, true},
+		{"server name in a foreign namespace", metav1.ObjectMeta{Name: "netperf-server-123", Namespace: "tests",
+			Labels: labels(netperfTypeServer)}, "", false},
+		{"client name in a foreign namespace", metav1.ObjectMeta{Name: "netperf-client-123", Namespace: "other",
+			Labels: labels(netperfTypeClient)}, "", false},
+		{"without labels", metav1.ObjectMeta{Name: "netperf-client-123", Namespace: "tests"}, "", false},
+		{"other test", metav1.ObjectMeta{Name: "netperf-client-123", Namespace: "tests",
+			Labels: map[string]string{"netperf-id": "ba9876543210", "netperf-type": "client"}}, "", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			npType, found := n.getTestPodType(cr, &v1.Pod{ObjectMeta: tt.pod})
+			if npType != tt.wantType || found != tt.wantFound {
+				t.Errorf("Netperf.getTestPodType() = %v, %v, want %v, %v", npType, found, tt.wantType, tt.wantFound)
+			}
+		})
+	}
+}
@@ -0,0 +1,137 @@
+package operator
+
+import (
+	"strconv"
+	"unicode/utf8"
+
+	"github.com/piontec/netperf-operator/pkg/apis/app/v1alpha1"
+	"github.com/sirupsen/logrus"
+	"k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+const (
+	// maxOutputBytes limits the size of each log stored in the output ConfigMap,
+	// so that it stays well below the size limit of the object
+	maxOutputBytes        = 64 * 1024
+	truncatedMarker       = "[output truncated]\n"
+	outputRunLabel        = "netperf-run"
+	outputConfigMapPrefix = "netperf-output-"
+)
+
+func (n *Netperf) getOutputConfigMapName(cr *v1alpha1.Netperf) string {
+	return outputConfigMapPrefix + n.getNetperfSuffix(cr)
+}
+
+// truncateOutput keeps the end of the output, as that's where netperf prints results and errors.
+// The cut is moved forward to the start of a rune, so that the output stays valid UTF-8.
+func (n *Netperf) truncateOutput(output string) string {
+	if len(output) <= maxOutputBytes {
+		return output
+	}
+	start := len(output) - maxOutputBytes + len(truncatedMarker)
+	for start < len(output) && !utf8.RuneStart(output[start]) {
+		start++
+	}
+	return truncatedMarker + output[start:]
+}
+
+func (n *Netperf) newOutputConfigMap(cr *v1alpha1.Netperf) *v1.ConfigMap {
+	return &v1.ConfigMap{
+		TypeMeta: metav1.TypeMeta{
+			Kind:       "ConfigMap",
+			APIVersion: "v1",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      n.getOutputConfigMapName(cr),
+			Namespace: cr.Namespace,
+			OwnerReferences: []metav1.OwnerReference{
+				*metav1.NewControllerRef(cr, schema.GroupVersionKind{
+					Group:   v1alpha1.SchemeGroupVersion.Group,
+					Version: v1alpha1.SchemeGroupVersion.Version,
+					Kind:    "Netperf",
+				}),
+			},
+			Labels: map[string]string{
+				"app":          "netperf-operator",
+				"netperf-id":   n.getNetperfSuffix(cr),
+				outputRunLabel: strconv.Itoa(cr.Status.Run),
+			},
+		},
+		Data: map[string]string{},
+	}
+}
+
+// saveOutput stores raw logs of the test pods in a ConfigMap owned by the Netperf object.
+// Logs are stored under "<pod name>.log" keys, logs of previous runs are replaced.
+func (n *Netperf) saveOutput(cr *v1alpha1.Netperf, logs map[string]string) error {
+	configMap := n.newOutputConfigMap(cr)
+	existing := n.newOutputConfigMap(cr)
+	err := n.provider.Get(existing)
+	if err != nil && !errors.IsNotFound(err) {
+		logrus.Errorf("Failed to fetch output ConfigMap %s/%s: %v", configMap.Namespace, configMap.Name, err)
+		return err
+	}
+	found := err == nil
+	if found && existing.Labels[outputRunLabel] == configMap.Labels[outputRunLabel] {
+		for key, value := range existing.Data {
+			configMap.Data[key] = value
+		}
+	}
+	for pod, output := range logs {
+		configMap.Data[pod+".log"] = n.truncateOutput(output)
+	}
+
+	if found {
+		configMap.ResourceVersion = existing.ResourceVersion
+		err = n.provider.Update(configMap)
+	} else {
+		err = n.provider.Create(configMap)
+	}
+	if err != nil {
+		logrus.Errorf("Failed to save output to ConfigMap %s/%s: %v", configMap.Namespace, configMap.Name, err)
+		return err
+	}
+	logrus.Debugf("Output of netperf %s saved to ConfigMap %s", cr.Name, configMap.Name)
+	return nil
+}
+
+// saveTestOutput saves logs of the client pod and, if it can be found, of the server pod.
+// Failing to save them doesn't fail the test.
+func (n *Netperf) saveTestOutput(cr *v1alpha1.Netperf, clientPod *v1.Pod, clientLog string) string {
+	logs := map[string]string{clientPod.Name: clientLog}
+	if serverPod, err := n.getPodByName(cr.Status.ServerPod, n.getPodNamespace(cr, netperfTypeServer)); err == nil {
+		logs[serverPod.Name] = n.getLogFromPod(serverPod)
+	}
+	if err := n.saveOutput(cr, logs); err != nil {
+		return ""
+	}
+	return n.getOutputConfigMapName(cr)
+}
+
+// saveFailedTestOutput saves logs of the test pods that still exist before a failed test is
+// finished, as its pods might be deleted then. It returns the Netperf with the Output set.
+func (n *Netperf) saveFailedTestOutput(cr *v1alpha1.Netperf) *v1alpha1.Netperf {
+	logs := map[string]string{}
+	for npType, name := range map[netperfType]string{
+		netperfTypeServer: cr.Status.ServerPod,
+		netperfTypeClient: cr.Status.ClientPod,
+	} {
+		if name == "" {
+			continue
+		}
+		pod, err := n.getPodByName(name, n.getPodNamespace(cr, npType))
+		if err != nil {
+			continue
+		}
+		logs[pod.Name] = n.getLogFromPod(pod)
+	}
+	if len(logs) == 0 || n.saveOutput(cr, logs) != nil {
+		return cr
+	}
+	cr = cr.DeepCopy()
+	cr.Status.Output = n.getOutputConfigMapName(cr)
+	return cr
+}
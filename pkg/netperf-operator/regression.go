@@ -0,0 +1,135 @@
+package operator
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/piontec/netperf-operator/pkg/apis/app/v1alpha1"
+	"github.com/sirupsen/logrus"
+	"k8s.io/api/core/v1"
+)
+
+const (
+	defaultMaxThroughputDropPercent = 10
+	eventRegressionDetected         = "RegressionDetected"
+)
+
+// getBaselineResult returns the baseline throughput and p99 latency together with
+// a description of where they come from. Found is false if there are no baseline results yet.
+func (n *Netperf) getBaselineResult(cr *v1alpha1.Netperf) (result v1alpha1.NetperfRunResult, source string, found bool, err error) {
+	baseline := cr.Spec.Baseline
+	switch {
+	case baseline.Netperf != "":
+		other, err := n.getNetperfByName(baseline.Netperf, cr.Namespace)
+		if err != nil {
+			return result, "", false, err
+		}
+		if other.Status.Status != v1alpha1.NetperfPhaseDone {
+			return result, "", false, nil
+		}
+		result.SpeedBitsPerSec = other.Status.SpeedBitsPerSec
+		if other.Status.Stats != nil {
+			result.P99LatencyMicroseconds = other.Status.Stats.P99LatencyMicroseconds
+		}
+		return result, "netperf " + baseline.Netperf, true, nil
+	case baseline.SpeedBitsPerSec > 0 || baseline.P99LatencyMicroseconds > 0:
+		result.SpeedBitsPerSec = baseline.SpeedBitsPerSec
+		result.P99LatencyMicroseconds = baseline.P99LatencyMicroseconds
+		return result, "stored result", true, nil
+	case baseline.HistoryRuns > 0:
+		return n.getHistoryAverage(cr.Status.History, baseline.HistoryRuns)
+	}
+	return result, "", false, nil
+}
+
+// getHistoryAverage returns the average results of the last successful runs in the history
+func (n *Netperf) getHistoryAverage(history []v1alpha1.NetperfRunResult, runs int) (v1alpha1.NetperfRunResult, string, bool, error) {
+	var result v1alpha1.NetperfRunResult
+	var count, latencyCount int
+	for i := len(history) - 1; i >= 0 && count < runs; i-- {
+		if history[i].Status != v1alpha1.NetperfPhaseDone {
+			continue
+		}
+		count++
+		result.SpeedBitsPerSec += history[i].SpeedBitsPerSec
+		if history[i].P99LatencyMicroseconds > 0 {
+			latencyCount++
+			result.P99LatencyMicroseconds += history[i].P99LatencyMicroseconds
+		}
+	}
+	if count == 0 {
+		return result, "", false, nil
+	}
+	result.SpeedBitsPerSec /= float64(count)
+	if latencyCount > 0 {
+		result.P99LatencyMicroseconds /= float64(latencyCount)
+	}
+	return result, fmt.Sprintf("average of last %d runs", count), true, nil
+}
+
+func (n *Netperf) getChangePercent(baseline, value float64) float64 {
+	return (value - baseline) / baseline * 100
+}
+
+// compareWithBaseline computes changes of the results relative to the baseline
+func (n *Netperf) compareWithBaseline(baseline *v1alpha1.NetperfBaseline, result v1alpha1.NetperfRunResult,
+	status *v1alpha1.NetperfStatus) (*v1alpha1.NetperfRegression, []string) {
+	regression := &v1alpha1.NetperfRegression{
+		BaselineSpeedBitsPerSec:        result.SpeedBitsPerSec,
+		BaselineP99LatencyMicroseconds: result.P99LatencyMicroseconds,
+	}
+	var problems []string
+	maxDrop := baseline.MaxThroughputDropPercent
+	if maxDrop <= 0 {
+		maxDrop = defaultMaxThroughputDropPercent
+	}
+	if result.SpeedBitsPerSec > 0 {
+		regression.ThroughputChangePercent = n.getChangePercent(result.SpeedBitsPerSec, status.SpeedBitsPerSec)
+		if -regression.ThroughputChangePercent > maxDrop {
+			problems = append(problems, fmt.Sprintf("throughput dropped by %.1f%%", -regression.ThroughputChangePercent))
+		}
+	}
+	if result.P99LatencyMicroseconds > 0 && status.Stats != nil && status.Stats.P99LatencyMicroseconds > 0 {
+		// latency is better when it's lower
+		regression.LatencyChangePercent = -n.getChangePercent(result.P99LatencyMicroseconds, status.Stats.P99LatencyMicroseconds)
+		if baseline.MaxLatencyIncreasePercent > 0 && -regression.LatencyChangePercent > baseline.MaxLatencyIncreasePercent {
+			problems = append(problems, fmt.Sprintf("p99 latency increased by %.1f%%", -regression.LatencyChangePercent))
+		}
+	}
+	regression.Regressed = len(problems) > 0
+	return regression, problems
+}
+
+// checkRegression compares results of the successfully finished run with its baseline.
+// It has to be called before the run is added to the history.
+func (n *Netperf) checkRegression(cr *v1alpha1.Netperf, status *v1alpha1.NetperfStatus) {
+	if cr.Spec.Baseline == nil {
+		return
+	}
+	result, source, found, err := n.getBaselineResult(cr)
+	if err != nil {
+		logrus.Errorf("Failed to get baseline of netperf %s/%s: %v", cr.Namespace, cr.Name, err)
+		return
+	}
+	if !found {
+		logrus.Debugf("No baseline results for netperf %s/%s yet", cr.Namespace, cr.Name)
+		return
+	}
+	regression, problems := n.compareWithBaseline(cr.Spec.Baseline, result, status)
+	regression.Baseline = source
+	status.Regression = regression
+
+	condition := v1alpha1.NetperfCondition{
+		Type:    v1alpha1.NetperfConditionRegressed,
+		Status:  string(v1.ConditionFalse),
+		Reason:  "WithinBaseline",
+		Message: "Results are within the allowed deviation from " + source,
+	}
+	if regression.Regressed {
+		condition.Status = string(v1.ConditionTrue)
+		condition.Reason = eventRegressionDetected
+		condition.Message = fmt.Sprintf("Compared to %s: %s", source, strings.Join(problems, ", "))
+		n.recorder.Event(cr, v1.EventTypeWarning, eventRegressionDetected, condition.Message)
+	}
+	n.setCondition(status, condition)
+}
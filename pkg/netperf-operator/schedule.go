@@ -0,0 +1,227 @@
+package operator
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"time"
+
+	"github.com/piontec/netperf-operator/pkg/apis/app/v1alpha1"
+	"github.com/piontec/netperf-operator/pkg/cron"
+	"github.com/sirupsen/logrus"
+	"k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+const (
+	defaultScheduleHistoryLimit = 3
+	// maxMissedScheduleTicks limits how many missed ticks are walked through, like in CronJobs
+	maxMissedScheduleTicks = 100
+)
+
+func (n *Netperf) HandleNetperfSchedule(o *v1alpha1.NetperfSchedule, deleted bool) error {
+	logrus.Debugf("New NetperfSchedule event, name: %s, deleted: %v", o.Name, deleted)
+	if deleted {
+		// created Netperf objects are removed by the garbage collector, as the schedule is their owner
+		return nil
+	}
+	return n.reconcileNetperfSchedule(o, time.Now())
+}
+
+// getScheduleTick returns the latest tick of the schedule, that is due and wasn't handled yet.
+// Ticks older than the starting deadline are skipped. Like CronJobs, the schedule stops if too
+// many ticks were missed, until the deadline is set or decreased.
+func (n *Netperf) getScheduleTick(schedule *cron.Schedule, cr *v1alpha1.NetperfSchedule, now time.Time) (time.Time, bool, error) {
+	last := cr.CreationTimestamp.Time
+	if cr.Status.LastScheduleTime != nil {
+		last = cr.Status.LastScheduleTime.Time
+	}
+	if cr.Spec.StartingDeadlineSeconds != nil {
+		if earliest := now.Add(-time.Duration(*cr.Spec.StartingDeadlineSeconds) * time.Second); earliest.After(last) {
+			last = earliest
+		}
+	}
+	var tick time.Time
+	missed := 0
+	for next := schedule.Next(last); !next.IsZero() && !next.After(now); next = schedule.Next(next) {
+		tick = next
+		if missed++; missed > maxMissedScheduleTicks {
+			return time.Time{}, false, fmt.Errorf("more than %d ticks were missed, set or decrease startingDeadlineSeconds",
+				maxMissedScheduleTicks)
+		}
+	}
+	return tick, !tick.IsZero(), nil
+}
+
+func (n *Netperf) getScheduleHistoryLimit(cr *v1alpha1.NetperfSchedule) int {
+	if cr.Spec.HistoryLimit == nil {
+		return defaultScheduleHistoryLimit
+	}
+	return *cr.Spec.HistoryLimit
+}
+
+func (n *Netperf) getScheduledNetperfs(cr *v1alpha1.NetperfSchedule) ([]v1alpha1.Netperf, error) {
+	list := &v1alpha1.NetperfList{
+		TypeMeta: metav1.TypeMeta{
+			Kind:       "Netperf",
+			APIVersion: "app.example.com/v1alpha1",
+		},
+	}
+	selector := labels.SelectorFromSet(map[string]string{"netperf-schedule": cr.Name})
+	if err := n.provider.List(cr.Namespace, list, metav1.ListOptions{LabelSelector: selector.String()}); err != nil {
+		return nil, err
+	}
+	// list items don't always have their type set, but it's needed to delete them
+	for i := range list.Items {
+		list.Items[i].TypeMeta = metav1.TypeMeta{
+			Kind:       "Netperf",
+			APIVersion: "app.example.com/v1alpha1",
+		}
+	}
+	return list.Items, nil
+}
+
+func (n *Netperf) newScheduledNetperf(cr *v1alpha1.NetperfSchedule, tick time.Time) *v1alpha1.Netperf {
+	return &v1alpha1.Netperf{
+		TypeMeta: metav1.TypeMeta{
+			Kind:       "Netperf",
+			APIVersion: "app.example.com/v1alpha1",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      fmt.Sprintf("%s-%d", cr.Name, tick.Unix()),
+			Namespace: cr.Namespace,
+			OwnerReferences: []metav1.OwnerReference{
+				*metav1.NewControllerRef(cr, schema.GroupVersionKind{
+					Group:   v1alpha1.SchemeGroupVersion.Group,
+					Version: v1alpha1.SchemeGroupVersion.Version,
+					Kind:    "NetperfSchedule",
+				}),
+			},
+			Labels: map[string]string{
+				"app":              "netperf-operator",
+				"netperf-schedule": cr.Name,
+			},
+		},
+		Spec: cr.Spec.Template,
+	}
+}
+
+func (n *Netperf) deleteOldScheduledNetperfs(cr *v1alpha1.NetperfSchedule, finished []v1alpha1.Netperf) {
+	limit := n.getScheduleHistoryLimit(cr)
+	if len(finished) <= limit {
+		return
+	}
+	sort.Slice(finished, func(i, j int) bool {
+		return finished[i].CreationTimestamp.Before(&finished[j].CreationTimestamp)
+	})
+	for i := range finished[:len(finished)-limit] {
+		logrus.Debugf("Deleting old Netperf %s/%s of NetperfSchedule %s", cr.Namespace, finished[i].Name, cr.Name)
+		if err := n.provider.Delete(&finished[i]); err != nil && !errors.IsNotFound(err) {
+			logrus.Errorf("Failed to delete Netperf %s/%s: %v", cr.Namespace, finished[i].Name, err)
+		}
+	}
+}
+
+func (n *Netperf) reconcileNetperfSchedule(cr *v1alpha1.NetperfSchedule, now time.Time) error {
+	schedule, err := cron.Parse(cr.Spec.Schedule)
+	if err != nil {
+		// retrying won't help until the spec is fixed
+		logrus.Errorf("Invalid schedule in NetperfSchedule %s/%s: %v", cr.Namespace, cr.Name, err)
+		return n.stopNetperfSchedule(cr, eventInvalidSpec, fmt.Sprintf("Invalid schedule %q: %v", cr.Spec.Schedule, err))
+	}
+	netperfs, err := n.getScheduledNetperfs(cr)
+	if err != nil {
+		logrus.Errorf("Failed to list Netperf objects of NetperfSchedule %s/%s: %v", cr.Namespace, cr.Name, err)
+		return err
+	}
+
+	var active, finished []v1alpha1.Netperf
+	for _, netperf := range netperfs {
+		if n.isNetperfFinished(netperf.Status.Status) {
+			finished = append(finished, netperf)
+		} else {
+			active = append(active, netperf)
+		}
+	}
+	n.deleteOldScheduledNetperfs(cr, finished)
+
+	c := cr.DeepCopy()
+	c.Status.Active = nil
+	for _, netperf := range active {
+		c.Status.Active = append(c.Status.Active, netperf.Name)
+	}
+
+	tick, due, err := n.getScheduleTick(schedule, cr, now)
+	if err != nil {
+		logrus.Errorf("NetperfSchedule %s/%s can't run: %v", cr.Namespace, cr.Name, err)
+		if c.Status.Message != err.Error() {
+			n.recorder.Eventf(cr, v1.EventTypeWarning, eventMissedSchedule, "Schedule stopped: %v", err)
+		}
+		c.Status.Message = err.Error()
+	} else {
+		c.Status.Message = ""
+	}
+	if due && !cr.Spec.Suspend {
+		if err := n.runScheduledNetperf(c, active, tick); err != nil {
+			return err
+		}
+	}
+
+	if reflect.DeepEqual(cr.Status, c.Status) {
+		return nil
+	}
+	return n.provider.Update(c)
+}
+
+// stopNetperfSchedule records the reason why the schedule can't run in an event and in its status
+func (n *Netperf) stopNetperfSchedule(cr *v1alpha1.NetperfSchedule, reason, message string) error {
+	if cr.Status.Message == message {
+		return nil
+	}
+	n.recorder.Eventf(cr, v1.EventTypeWarning, reason, message)
+	c := cr.DeepCopy()
+	c.Status.Message = message
+	return n.provider.Update(c)
+}
+
+// runScheduledNetperf creates a Netperf object for the tick according to the concurrency policy
+// and updates the status of the schedule. With the "Forbid" policy the tick is skipped if the
+// previous Netperf is still running.
+func (n *Netperf) runScheduledNetperf(cr *v1alpha1.NetperfSchedule, active []v1alpha1.Netperf, tick time.Time) error {
+	cr.Status.LastScheduleTime = &metav1.Time{Time: tick}
+	switch cr.Spec.ConcurrencyPolicy {
+	case v1alpha1.NetperfConcurrencyForbid:
+		if len(active) > 0 {
+			logrus.Debugf("Skipping run of NetperfSchedule %s at %v, previous run is still active", cr.Name, tick)
+			return nil
+		}
+	case v1alpha1.NetperfConcurrencyReplace:
+		for i := range active {
+			logrus.Debugf("Replacing active Netperf %s of NetperfSchedule %s", active[i].Name, cr.Name)
+			if err := n.provider.Delete(&active[i]); err != nil && !errors.IsNotFound(err) {
+				logrus.Errorf("Failed to delete Netperf %s/%s: %v", cr.Namespace, active[i].Name, err)
+				return err
+			}
+		}
+		cr.Status.Active = nil
+	}
+
+	netperf := n.newScheduledNetperf(cr, tick)
+	if err := n.provider.Create(netperf); err != nil && !errors.IsAlreadyExists(err) {
+		logrus.Errorf("Failed to create Netperf %s/%s for NetperfSchedule %s: %v", netperf.Namespace,
+			netperf.Name, cr.Name, err)
+		return err
+	}
+	logrus.Debugf("NetperfSchedule %s started Netperf %s", cr.Name, netperf.Name)
+	cr.Status.LastNetperf = netperf.Name
+	for _, name := range cr.Status.Active {
+		if name == netperf.Name {
+			return nil
+		}
+	}
+	cr.Status.Active = append(cr.Status.Active, netperf.Name)
+	return nil
+}
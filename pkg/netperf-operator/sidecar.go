@@ -0,0 +1,99 @@
+package operator
+
+import (
+	"github.com/piontec/netperf-operator/pkg/apis/app/v1alpha1"
+	"github.com/sirupsen/logrus"
+	"k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const sidecarInjectLabel = "sidecar.istio.io/inject"
+
+// usesSidecar tells if pods of the current run get the sidecar
+func (n *Netperf) usesSidecar(cr *v1alpha1.Netperf) bool {
+	return cr.Spec.Sidecar != nil && (!cr.Spec.Sidecar.Compare || cr.Status.SidecarInjected)
+}
+
+// needsSidecarRun tells if the run without the sidecar is finished and the test has to be repeated with it
+func (n *Netperf) needsSidecarRun(cr *v1alpha1.Netperf) bool {
+	return cr.Spec.Sidecar != nil && cr.Spec.Sidecar.Compare && !cr.Status.SidecarInjected
+}
+
+func (n *Netperf) getSidecarMetadata(cr *v1alpha1.Netperf) v1alpha1.NetperfPodMetadata {
+	metadata, value := cr.Spec.Sidecar.WithoutSidecar, "false"
+	if n.usesSidecar(cr) {
+		metadata, value = cr.Spec.Sidecar.WithSidecar, "true"
+	}
+	if len(metadata.Labels) == 0 && len(metadata.Annotations) == 0 {
+		metadata.Labels = map[string]string{sidecarInjectLabel: value}
+	}
+	return metadata
+}
+
+// setSidecarMetadata adds labels and annotations controlling the sidecar injection to the test pod
+func (n *Netperf) setSidecarMetadata(cr *v1alpha1.Netperf, meta *metav1.ObjectMeta) {
+	if cr.Spec.Sidecar == nil {
+		return
+	}
+	metadata := n.getSidecarMetadata(cr)
+	for k, v := range metadata.Labels {
+		meta.Labels[k] = v
+	}
+	if len(metadata.Annotations) > 0 && meta.Annotations == nil {
+		meta.Annotations = map[string]string{}
+	}
+	for k, v := range metadata.Annotations {
+		meta.Annotations[k] = v
+	}
+}
+
+// isClientPodSucceeded tells if the test container finished successfully. The pod doesn't
+// succeed while its sidecar keeps running.
+func (n *Netperf) isClientPodSucceeded(pod *v1.Pod) bool {
+	if pod.Status.Phase == v1.PodSucceeded {
+		return true
+	}
+	for _, status := range pod.Status.ContainerStatuses {
+		if status.Name == pod.Name {
+			return status.State.Terminated != nil && status.State.Terminated.ExitCode == 0
+		}
+	}
+	return false
+}
+
+// startSidecarRun records the results of the run without the sidecar, deletes its pods and
+// starts the test again with the sidecar. All the pods have to be recreated, because the
+// sidecar is injected when a pod is created.
+func (n *Netperf) startSidecarRun(cr *v1alpha1.Netperf, status *v1alpha1.NetperfStatus) error {
+	logrus.Debugf("Test without sidecar completed for netperf %s, running it with sidecar", cr.Name)
+	if err := n.deleteTestPods(cr); err != nil {
+		n.updateNetperfStatus(cr, v1alpha1.NetperfPhaseError)
+		return err
+	}
+	netperf := cr.DeepCopy()
+	netperf.Status.Sidecar = &v1alpha1.NetperfSidecarResult{WithoutSidecarSpeedBitsPerSec: status.SpeedBitsPerSec}
+	if status.Stats != nil {
+		netperf.Status.Sidecar.WithoutSidecarP99LatencyMicroseconds = status.Stats.P99LatencyMicroseconds
+	}
+	netperf.Status.SidecarInjected = true
+	netperf.Status.ServerPod = ""
+	netperf.Status.ClientPod = ""
+	netperf.Status.Status = v1alpha1.NetperfPhaseInitial
+	return n.provider.Update(netperf)
+}
+
+// setSidecarOverhead compares results of the runs with and without the sidecar
+func (n *Netperf) setSidecarOverhead(status *v1alpha1.NetperfStatus) {
+	result := status.Sidecar
+	if result == nil {
+		return
+	}
+	if result.WithoutSidecarSpeedBitsPerSec > 0 {
+		result.ThroughputOverheadPercent = -n.getChangePercent(result.WithoutSidecarSpeedBitsPerSec, status.SpeedBitsPerSec)
+	}
+	if result.WithoutSidecarP99LatencyMicroseconds > 0 && status.Stats != nil && status.Stats.P99LatencyMicroseconds > 0 {
+		result.LatencyOverheadMicroseconds = status.Stats.P99LatencyMicroseconds - result.WithoutSidecarP99LatencyMicroseconds
+		result.LatencyOverheadPercent = n.getChangePercent(result.WithoutSidecarP99LatencyMicroseconds,
+			status.Stats.P99LatencyMicroseconds)
+	}
+}
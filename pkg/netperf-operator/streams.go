@@ -0,0 +1,208 @@
+package operator
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/piontec/netperf-operator/pkg/apis/app/v1alpha1"
+)
+
+const (
+	streamOutputPrefix = "stream"
+	netperfTestStream  = "TCP_STREAM"
+	netperfTestMaerts  = "TCP_MAERTS"
+)
+
+type netperfStream struct {
+	id        int
+	test      string
+	direction string
+}
+
+func (n *Netperf) getStreamCount(cr *v1alpha1.Netperf) int {
+	if cr.Spec.ParallelStreams < 1 {
+		return 1
+	}
+	return cr.Spec.ParallelStreams
+}
+
+func (n *Netperf) getDirection(cr *v1alpha1.Netperf) string {
+	if cr.Spec.Direction == "" {
+		return v1alpha1.NetperfDirectionClientToServer
+	}
+	return cr.Spec.Direction
+}
+
+// getNetperfStreams returns all the netperf instances run by the client pod. TCP_STREAM sends data
+// from the client to the server, TCP_MAERTS the other way round. In the bidirectional mode both
+// run at the same time.
+func (n *Netperf) getNetperfStreams(cr *v1alpha1.Netperf) []netperfStream {
+	var directions []string
+	switch n.getDirection(cr) {
+	case v1alpha1.NetperfDirectionServerToClient:
+		directions = []string{v1alpha1.NetperfDirectionServerToClient}
+	case v1alpha1.NetperfDirectionBidirectional:
+		directions = []string{v1alpha1.NetperfDirectionClientToServer, v1alpha1.NetperfDirectionServerToClient}
+	default:
+		directions = []string{v1alpha1.NetperfDirectionClientToServer}
+	}
+
+	var streams []netperfStream
+	for _, direction := range directions {
+		test := netperfTestStream
+		if direction == v1alpha1.NetperfDirectionServerToClient {
+			test = netperfTestMaerts
+		}
+		for i := 0; i < n.getStreamCount(cr); i++ {
+			streams = append(streams, netperfStream{id: len(streams) + 1, test: test, direction: direction})
+		}
+	}
+	return streams
+}
+
+// getNetperfTestType returns the name of the netperf test run
+func (n *Netperf) getNetperfTestType(cr *v1alpha1.Netperf) string {
+	switch n.getDirection(cr) {
+	case v1alpha1.NetperfDirectionServerToClient:
+		return netperfTestMaerts
+	case v1alpha1.NetperfDirectionBidirectional:
+		return netperfTestStream + "," + netperfTestMaerts
+	default:
+		return netperfTestStream
+	}
+}
+
+func (n *Netperf) usesParallelCommand(cr *v1alpha1.Netperf) bool {
+	return len(n.getNetperfStreams(cr)) > 1
+}
+
+// getParallelClientCommand runs all the netperf instances in background and prints their
+// results once all of them are done, one "stream <n>: <netperf output>" line per instance
+func (n *Netperf) getParallelClientCommand(cr *v1alpha1.Netperf, serverIP string) []string {
+	var script, ids []string
+	for _, stream := range n.getNetperfStreams(cr) {
+		netperf := []string{"netperf", "-H", serverIP, "-t", stream.test, "-P", "0"}
+		netperf = append(netperf, n.getNetperfOptions(cr, netperfDataPort+stream.id-1)...)
+		script = append(script, fmt.Sprintf("%s > /tmp/netperf-%d.out 2>&1 &", strings.Join(netperf, " "), stream.id))
+		ids = append(ids, strconv.Itoa(stream.id))
+	}
+	script = append(script, "wait;", fmt.Sprintf(`for i in %s; do echo "%s $i: $(cat /tmp/netperf-$i.out)"; done`,
+		strings.Join(ids, " "), streamOutputPrefix))
+	return []string{"sh", "-c", strings.Join(script, " ")}
+}
+
+func (n *Netperf) parseParallelNetperfResult(result string, streams []netperfStream) ([]v1alpha1.NetperfStreamResult, error) {
+	directions := map[int]string{}
+	for _, s := range streams {
+		directions[s.id] = s.direction
+	}
+	var results []v1alpha1.NetperfStreamResult
+	for _, line := range strings.Split(result, "\n") {
+		if !strings.HasPrefix(line, streamOutputPrefix+" ") {
+			continue
+		}
+		parts := strings.SplitN(strings.TrimPrefix(line, streamOutputPrefix+" "), ":", 2)
+		if len(parts) != 2 {
+			return nil, fmt.Errorf("Bad stream result line: %s", line)
+		}
+		stream, err := strconv.Atoi(parts[0])
+		if err != nil {
+			return nil, fmt.Errorf("Bad stream number in line %s: %v", line, err)
+		}
+		direction, found := directions[stream]
+		if !found {
+			return nil, fmt.Errorf("Unexpected stream number in line %s", line)
+		}
+		speed, err := n.parseStreamThroughput(parts[1])
+		if err != nil {
+			return nil, fmt.Errorf("Bad netperf output for stream %d: %v", stream, err)
+		}
+		results = append(results, v1alpha1.NetperfStreamResult{
+			Stream:          stream,
+			Direction:       direction,
+			SpeedBitsPerSec: speed,
+		})
+	}
+	if len(results) != len(streams) {
+		return nil, fmt.Errorf("Expected results of %d streams, got %d", len(streams), len(results))
+	}
+	return results, nil
+}
+
+// parseStreamThroughput parses netperf output printed without the banner (-P 0)
+func (n *Netperf) parseStreamThroughput(output string) (float64, error) {
+	if throughput, found := n.parseKeyvalThroughput(output); found {
+		return throughput, nil
+	}
+	entries := strings.Fields(output)
+	if len(entries) < 5 {
+		return 0, fmt.Errorf("%s", output)
+	}
+	return strconv.ParseFloat(entries[4], 64)
+}
+
+// getStreamsFairness compares the streams going in the direction, or all of them if it's empty
+func (n *Netperf) getStreamsFairness(streams []v1alpha1.NetperfStreamResult, direction string) *v1alpha1.NetperfFairness {
+	var selected []v1alpha1.NetperfStreamResult
+	for _, s := range streams {
+		if direction == "" || s.Direction == direction {
+			selected = append(selected, s)
+		}
+	}
+	if len(selected) == 0 {
+		return nil
+	}
+	fairness := &v1alpha1.NetperfFairness{
+		MinBitsPerSec: math.Inf(1),
+		MaxBitsPerSec: math.Inf(-1),
+	}
+	var sum, sumSquares float64
+	for _, s := range selected {
+		fairness.MinBitsPerSec = math.Min(fairness.MinBitsPerSec, s.SpeedBitsPerSec)
+		fairness.MaxBitsPerSec = math.Max(fairness.MaxBitsPerSec, s.SpeedBitsPerSec)
+		sum += s.SpeedBitsPerSec
+		sumSquares += s.SpeedBitsPerSec * s.SpeedBitsPerSec
+	}
+	count := float64(len(selected))
+	fairness.MeanBitsPerSec = sum / count
+	fairness.StdDevBitsPerSec = math.Sqrt(math.Max(sumSquares/count-fairness.MeanBitsPerSec*fairness.MeanBitsPerSec, 0))
+	if sumSquares > 0 {
+		fairness.JainIndex = sum * sum / (count * sumSquares)
+	}
+	return fairness
+}
+
+func (n *Netperf) getStreamsThroughput(streams []v1alpha1.NetperfStreamResult, direction string) float64 {
+	var sum float64
+	for _, s := range streams {
+		if direction == "" || s.Direction == direction {
+			sum += s.SpeedBitsPerSec
+		}
+	}
+	return sum
+}
+
+// setThroughputResults fills in the throughput fields of the status. If the test was run using
+// a single netperf instance, streams are nil and throughput is the result of that instance.
+func (n *Netperf) setThroughputResults(cr *v1alpha1.Netperf, status *v1alpha1.NetperfStatus,
+	throughput float64, streams []v1alpha1.NetperfStreamResult) {
+	if streams == nil {
+		streams = []v1alpha1.NetperfStreamResult{
+			{Stream: 1, Direction: n.getDirection(cr), SpeedBitsPerSec: throughput},
+		}
+	} else {
+		status.Streams = streams
+		if n.getDirection(cr) == v1alpha1.NetperfDirectionBidirectional {
+			// streams of the two directions don't compete for the same bandwidth
+			status.ClientToServerFairness = n.getStreamsFairness(streams, v1alpha1.NetperfDirectionClientToServer)
+			status.ServerToClientFairness = n.getStreamsFairness(streams, v1alpha1.NetperfDirectionServerToClient)
+		} else {
+			status.Fairness = n.getStreamsFairness(streams, "")
+		}
+	}
+	status.SpeedBitsPerSec = n.getStreamsThroughput(streams, "")
+	status.ClientToServerBitsPerSec = n.getStreamsThroughput(streams, v1alpha1.NetperfDirectionClientToServer)
+	status.ServerToClientBitsPerSec = n.getStreamsThroughput(streams, v1alpha1.NetperfDirectionServerToClient)
+}
@@ -0,0 +1,92 @@
+package operator
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/piontec/netperf-operator/pkg/apis/app/v1alpha1"
+	"github.com/sirupsen/logrus"
+	"k8s.io/api/core/v1"
+)
+
+// minNamespaceNameLabelMinor is the first Kubernetes 1.x release, that labels namespaces with
+// their name. NetworkPolicies of cross-namespace tests select the peer namespace by this label
+// in a peer combining pod and namespace selectors, which needs 1.11 and newer too.
+const minNamespaceNameLabelMinor = 21
+
+// validateSpec checks the settings, that can't be tested together
+func (n *Netperf) validateSpec(cr *v1alpha1.Netperf) error {
+	switch cr.Spec.Direction {
+	case "", v1alpha1.NetperfDirectionClientToServer, v1alpha1.NetperfDirectionServerToClient,
+		v1alpha1.NetperfDirectionBidirectional:
+	default:
+		return fmt.Errorf("unknown direction %q", cr.Spec.Direction)
+	}
+	switch cr.Spec.Backend {
+	case "", v1alpha1.NetperfBackendNetperf, v1alpha1.NetperfBackendIperf3, v1alpha1.NetperfBackendLatency,
+		v1alpha1.NetperfBackendHTTP, v1alpha1.NetperfBackendDNS, v1alpha1.NetperfBackendMTU:
+	default:
+		return fmt.Errorf("unknown backend %q", cr.Spec.Backend)
+	}
+	switch cr.Spec.CleanupPolicy {
+	case "", v1alpha1.NetperfCleanupAlways, v1alpha1.NetperfCleanupOnSuccess, v1alpha1.NetperfCleanupNever:
+	default:
+		return fmt.Errorf("unknown cleanupPolicy %q", cr.Spec.CleanupPolicy)
+	}
+	switch cr.Spec.NetworkPolicy {
+	case v1alpha1.NetperfNetworkPolicyNone, v1alpha1.NetperfNetworkPolicyAllow, v1alpha1.NetperfNetworkPolicyEnforced:
+	default:
+		return fmt.Errorf("unknown networkPolicy %q", cr.Spec.NetworkPolicy)
+	}
+	if cr.Spec.Backend == v1alpha1.NetperfBackendLatency && n.usesNetworkPolicy(cr) &&
+		(&latencyBackend{n: n}).getSpec(cr).Mode == v1alpha1.NetperfLatencyModeICMP {
+		return fmt.Errorf("networkPolicy can't allow ICMP, use the tcp or sockperf latency mode")
+	}
+	if n.isCrossNamespace(cr) && n.usesNetworkPolicy(cr) && !n.hasNamespaceNameLabel() {
+		return fmt.Errorf("networkPolicy of tests in other namespaces needs Kubernetes 1.%d or newer",
+			minNamespaceNameLabelMinor)
+	}
+	return nil
+}
+
+// rejectSpec fails the test, that has an invalid spec
+func (n *Netperf) rejectSpec(cr *v1alpha1.Netperf, err error) error {
+	logrus.Debugf("Invalid spec of netperf %s/%s: %v", cr.Namespace, cr.Name, err)
+	n.recorder.Eventf(cr, v1.EventTypeWarning, eventInvalidSpec, "Invalid spec: %v", err)
+	return n.updateNetperfStatus(cr, v1alpha1.NetperfPhaseError)
+}
+
+// hasNamespaceNameLabel tells if the cluster labels namespaces with their name. If the version
+// of the cluster is unknown, the label is expected to exist.
+func (n *Netperf) hasNamespaceNameLabel() bool {
+	client := n.provider.GetKubeClient()
+	if client == nil {
+		return true
+	}
+	info, err := client.Discovery().ServerVersion()
+	if err != nil {
+		logrus.Errorf("Failed to get the version of the cluster: %v", err)
+		return true
+	}
+	major, minor, ok := n.parseServerVersion(info.Major, info.Minor)
+	if !ok {
+		logrus.Debugf("Unknown version of the cluster: %s.%s", info.Major, info.Minor)
+		return true
+	}
+	return major > 1 || minor >= minNamespaceNameLabelMinor
+}
+
+// parseServerVersion reads the major and minor versions reported by the apiserver, which may
+// have a suffix like "21+"
+func (n *Netperf) parseServerVersion(major, minor string) (int, int, bool) {
+	majorNumber, err := strconv.Atoi(major)
+	if err != nil {
+		return 0, 0, false
+	}
+	minorNumber, err := strconv.Atoi(strings.TrimRight(minor, "+"))
+	if err != nil {
+		return 0, 0, false
+	}
+	return majorNumber, minorNumber, true
+}
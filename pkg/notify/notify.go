@@ -0,0 +1,151 @@
+// Package notify sends notifications about finished Netperf tests to webhooks
+package notify
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"text/template"
+	"time"
+
+	"github.com/piontec/netperf-operator/pkg/apis/app/v1alpha1"
+	"github.com/sirupsen/logrus"
+)
+
+// notification events
+const (
+	EventCompleted = "completed"
+	EventFailed    = "failed"
+	EventRegressed = "regressed"
+)
+
+const (
+	defaultRetries = 3
+	defaultBackoff = time.Second
+	defaultTimeout = 10 * time.Second
+)
+
+// Notification describes a finished test. It's sent as JSON, unless a template is used.
+type Notification struct {
+	Event      string                      `json:"event"`
+	Time       time.Time                   `json:"time"`
+	Namespace  string                      `json:"namespace"`
+	Name       string                      `json:"name"`
+	Run        int                         `json:"run"`
+	ServerNode string                      `json:"serverNode"`
+	ClientNode string                      `json:"clientNode"`
+	Parameters Parameters                  `json:"parameters"`
+	Results    *Results                    `json:"results,omitempty"`
+	Verdict    string                      `json:"verdict,omitempty"`
+	Regression *v1alpha1.NetperfRegression `json:"regression,omitempty"`
+}
+
+type Parameters struct {
+	TestType        string `json:"testType"`
+	Direction       string `json:"direction"`
+	ParallelStreams int    `json:"parallelStreams"`
+	NetworkPolicy   string `json:"networkPolicy,omitempty"`
+}
+
+type Results struct {
+	SpeedBitsPerSec          float64                `json:"speedBitsPerSec"`
+	ClientToServerBitsPerSec float64                `json:"clientToServerBitsPerSec,omitempty"`
+	ServerToClientBitsPerSec float64                `json:"serverToClientBitsPerSec,omitempty"`
+	Stats                    *v1alpha1.NetperfStats `json:"stats,omitempty"`
+}
+
+// Notifier delivers notifications. Notify must not block the caller.
+type Notifier interface {
+	Notify(notification Notification)
+}
+
+// Webhook POSTs notifications to the URL
+type Webhook struct {
+	URL string
+	// Template renders the body, if it's nil, the notification is sent as JSON
+	Template *template.Template
+	// Retries is the number of additional attempts, each waiting twice as long as the previous one
+	Retries int
+	Backoff time.Duration
+	Client  *http.Client
+}
+
+// NewWebhook returns a webhook with the default retry policy. If templateText isn't empty,
+// it's a text/template rendering the body from the Notification, the "json" function
+// can be used to quote values.
+func NewWebhook(url, templateText string) (*Webhook, error) {
+	webhook := &Webhook{
+		URL:     url,
+		Retries: defaultRetries,
+		Backoff: defaultBackoff,
+		Client:  &http.Client{Timeout: defaultTimeout},
+	}
+	if templateText != "" {
+		tmpl, err := template.New("webhook").Funcs(template.FuncMap{"json": toJSON}).Parse(templateText)
+		if err != nil {
+			return nil, err
+		}
+		webhook.Template = tmpl
+	}
+	return webhook, nil
+}
+
+func toJSON(value interface{}) (string, error) {
+	data, err := json.Marshal(value)
+	return string(data), err
+}
+
+func (w *Webhook) Notify(notification Notification) {
+	go func() {
+		if err := w.Send(notification); err != nil {
+			logrus.Errorf("Failed to send %s notification of netperf %s/%s: %v", notification.Event,
+				notification.Namespace, notification.Name, err)
+		}
+	}()
+}
+
+func (w *Webhook) render(notification Notification) ([]byte, error) {
+	if w.Template == nil {
+		return json.Marshal(notification)
+	}
+	var body bytes.Buffer
+	if err := w.Template.Execute(&body, notification); err != nil {
+		return nil, err
+	}
+	return body.Bytes(), nil
+}
+
+// Send POSTs the notification and waits for the result. Server errors and failed requests
+// are retried.
+func (w *Webhook) Send(notification Notification) error {
+	body, err := w.render(notification)
+	if err != nil {
+		return fmt.Errorf("rendering body: %v", err)
+	}
+	backoff := w.Backoff
+	for attempt := 0; ; attempt++ {
+		retry, err := w.post(body)
+		if err == nil || !retry || attempt >= w.Retries {
+			return err
+		}
+		logrus.Debugf("Webhook request failed, retrying in %v: %v", backoff, err)
+		time.Sleep(backoff)
+		backoff *= 2
+	}
+}
+
+func (w *Webhook) post(body []byte) (bool, error) {
+	resp, err := w.Client.Post(w.URL, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return true, err
+	}
+	defer resp.Body.Close()
+	ioutil.ReadAll(resp.Body)
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return false, nil
+	}
+	retry := resp.StatusCode >= 500 || resp.StatusCode == http.StatusTooManyRequests
+	return retry, fmt.Errorf("webhook returned %s", resp.Status)
+}
@@ -0,0 +1,59 @@
+package notify
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestWebhook_Send(t *testing.T) {
+	var requests int
+	var received Notification
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		if requests == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		if err := json.NewDecoder(r.Body).Decode(&received); err != nil {
+			t.Errorf("Failed to decode the notification: %v", err)
+		}
+	}))
+	defer server.Close()
+
+	webhook, err := NewWebhook(server.URL, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	webhook.Backoff = 0
+	notification := Notification{Event: EventCompleted, Name: "example", Results: &Results{SpeedBitsPerSec: 9000}}
+	if err := webhook.Send(notification); err != nil {
+		t.Fatalf("Webhook.Send() error = %v", err)
+	}
+	if requests != 2 || received.Name != "example" || received.Results.SpeedBitsPerSec != 9000 {
+		t.Errorf("Webhook.Send() delivered %+v in %d requests, want %+v in 2", received, requests, notification)
+	}
+}
+
+func TestWebhook_SendTemplate(t *testing.T) {
+	var body string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		data, _ := ioutil.ReadAll(r.Body)
+		body = string(data)
+		w.WriteHeader(http.StatusBadRequest)
+	}))
+	defer server.Close()
+
+	webhook, err := NewWebhook(server.URL, `{"text": {{printf "Netperf %s %s" .Name .Event | json}}}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := webhook.Send(Notification{Event: EventFailed, Name: `"quoted"`}); err == nil {
+		t.Errorf("Webhook.Send() didn't return error of the bad request")
+	}
+	if want := `{"text": "Netperf \"quoted\" failed"}`; body != want {
+		t.Errorf("Webhook.Send() body = %v, want %v", body, want)
+	}
+}
@@ -0,0 +1,184 @@
+package report
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/piontec/netperf-operator/pkg/apis/app/v1alpha1"
+)
+
+const (
+	FormatHTML     = "html"
+	FormatMarkdown = "markdown"
+)
+
+// Report is a self-contained document with results of tests and matrices
+type Report struct {
+	Title     string
+	Generated time.Time
+	Netperfs  []v1alpha1.Netperf
+	Matrices  []v1alpha1.NetperfMatrix
+}
+
+// Summary counts tests by their outcome. Tests without expectations pass if they're done.
+type Summary struct {
+	Total   int
+	Passed  int
+	Failed  int
+	Running int
+}
+
+// isFailed tells if the test finished with an error or didn't meet its expectations
+func isFailed(netperf v1alpha1.Netperf) bool {
+	return netperf.Status.Status == v1alpha1.NetperfPhaseError || netperf.Status.Verdict == v1alpha1.NetperfVerdictFail
+}
+
+func (r *Report) Summary() Summary {
+	var s Summary
+	for _, netperf := range r.Netperfs {
+		s.Total++
+		switch {
+		case isFailed(netperf):
+			s.Failed++
+		case netperf.Status.Status == v1alpha1.NetperfPhaseDone:
+			s.Passed++
+		default:
+			s.Running++
+		}
+	}
+	return s
+}
+
+// Write writes the report in one of the document formats. The table, JSON and CSV formats have
+// only the results of the tests, as written by WriteResults.
+func (r *Report) Write(w io.Writer, format string) error {
+	switch format {
+	case FormatHTML:
+		return writeHTML(w, r)
+	case FormatMarkdown, "md":
+		return writeMarkdown(w, r)
+	case FormatTable, FormatJSON, FormatCSV:
+		return WriteResults(w, format, r.Netperfs)
+	}
+	return fmt.Errorf("unknown report format %q", format)
+}
+
+// section is a heatmap of a node pair grid
+type section struct {
+	Title string
+	Grid  *Grid
+}
+
+// heatmaps returns a grid of each matrix and, if there are tests with known nodes,
+// a grid of the last results of the single tests
+func (r *Report) heatmaps() []section {
+	var sections []section
+	for i := range r.Matrices {
+		sections = append(sections, section{"NetperfMatrix " + r.Matrices[i].Name, NewGrid(&r.Matrices[i])})
+	}
+	if grid := NewNetperfGrid(r.Netperfs); len(grid.Nodes) > 1 {
+		sections = append(sections, section{"Netperf tests", grid})
+	}
+	return sections
+}
+
+// NewNetperfGrid returns a grid with the results of the latest finished test of each node pair.
+// Only tests measuring throughput are included.
+func NewNetperfGrid(netperfs []v1alpha1.Netperf) *Grid {
+	sorted := append([]v1alpha1.Netperf{}, netperfs...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return completionTime(sorted[i]).Before(completionTime(sorted[j]))
+	})
+	matrix := &v1alpha1.NetperfMatrix{}
+	for _, netperf := range sorted {
+		if netperf.Status.ServerNode == "" || netperf.Status.ClientNode == "" || !netperf.Spec.MeasuresThroughput() {
+			continue
+		}
+		matrix.Status.Results = append(matrix.Status.Results, v1alpha1.NetperfMatrixResult{
+			ServerNode:      netperf.Status.ServerNode,
+			ClientNode:      netperf.Status.ClientNode,
+			Netperf:         netperf.Name,
+			Status:          netperf.Status.Status,
+			SpeedBitsPerSec: netperf.Status.SpeedBitsPerSec,
+		})
+	}
+	return NewGrid(matrix)
+}
+
+func completionTime(netperf v1alpha1.Netperf) time.Time {
+	if netperf.Status.CompletionTime == nil {
+		return time.Time{}
+	}
+	return netperf.Status.CompletionTime.Time
+}
+
+// Range returns the lowest and highest throughput (or latency) in the grid
+func (g *Grid) Range() (float64, float64) {
+	min, max := math.Inf(1), math.Inf(-1)
+	for _, servers := range g.results {
+		for _, result := range servers {
+			if result.Status == v1alpha1.NetperfPhaseDone {
+				min = math.Min(min, g.value(result))
+				max = math.Max(max, g.value(result))
+			}
+		}
+	}
+	return min, max
+}
+
+// heatColor returns a color from red (the lowest value) to green (the highest one)
+func heatColor(value, min, max float64) string {
+	ratio := 1.0
+	if max > min {
+		ratio = (value - min) / (max - min)
+	}
+	return fmt.Sprintf("hsl(%d, 70%%, 75%%)", int(ratio*120))
+}
+
+// historyResults returns throughput (or the 99th percentile latency, if the backend doesn't
+// measure throughput) of the successful runs in the history, the oldest first
+func historyResults(netperf v1alpha1.Netperf) []float64 {
+	var values []float64
+	for _, result := range netperf.Status.History {
+		switch {
+		case result.Status != v1alpha1.NetperfPhaseDone:
+		case netperf.Spec.MeasuresThroughput():
+			values = append(values, result.SpeedBitsPerSec)
+		default:
+			values = append(values, result.P99LatencyMicroseconds)
+		}
+	}
+	return values
+}
+
+// normalize scales the values to the 0-1 range
+func normalize(values []float64) []float64 {
+	min, max := math.Inf(1), math.Inf(-1)
+	for _, v := range values {
+		min = math.Min(min, v)
+		max = math.Max(max, v)
+	}
+	result := make([]float64, len(values))
+	for i, v := range values {
+		if max > min {
+			result[i] = (v - min) / (max - min)
+		} else {
+			result[i] = 0.5
+		}
+	}
+	return result
+}
+
+// Sparkline renders the values using unicode block characters
+func Sparkline(values []float64) string {
+	blocks := []rune("▁▂▃▄▅▆▇█")
+	var line bytes.Buffer
+	for _, v := range normalize(values) {
+		line.WriteRune(blocks[int(math.Round(v*float64(len(blocks)-1)))])
+	}
+	return line.String()
+}
@@ -0,0 +1,106 @@
+package report
+
+import (
+	"fmt"
+	"html/template"
+	"io"
+	"strings"
+
+	"github.com/piontec/netperf-operator/pkg/apis/app/v1alpha1"
+)
+
+const (
+	sparklineWidth  = 120
+	sparklineHeight = 24
+)
+
+var htmlTemplate = template.Must(template.New("report").Funcs(template.FuncMap{
+	"row":       NewRow,
+	"time":      formatTime,
+	"sparkline": sparklineSVG,
+	"history":   historyResults,
+	"cell":      heatmapCell,
+	"failed":    isFailed,
+	"result":    doneResult,
+}).Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>{{.Title}}</title>
+<style>
+body { font-family: sans-serif; margin: 2em; color: #222; }
+table { border-collapse: collapse; margin-bottom: 2em; }
+th, td { border: 1px solid #ccc; padding: 4px 8px; text-align: right; }
+th { background: #f0f0f0; }
+td.text, th.text { text-align: left; }
+.pass { color: #2a7d2a; font-weight: bold; }
+.fail { color: #c62828; font-weight: bold; }
+.summary span { margin-right: 2em; }
+</style>
+</head>
+<body>
+<h1>{{.Title}}</h1>
+<p>Generated {{time .Generated}}</p>
+{{with .Summary}}<p class="summary"><span>Tests: {{.Total}}</span><span class="pass">Passed: {{.Passed}}</span><span class="fail">Failed: {{.Failed}}</span><span>Running: {{.Running}}</span></p>{{end}}
+{{if .Netperfs}}<h2>Tests</h2>
+<table>
+<tr><th class="text">Name</th><th class="text">Status</th><th class="text">Client</th><th class="text">Server</th><th class="text">Direction</th><th>Streams</th><th>Result</th><th class="text">Verdict</th><th class="text">History</th><th class="text">Completed</th></tr>
+{{range .Netperfs}}{{$row := row .}}<tr>
+<td class="text">{{$row.Name}}</td>
+<td class="text{{if failed .}} fail{{end}}">{{$row.Status}}</td>
+<td class="text">{{$row.ClientNode}}</td>
+<td class="text">{{$row.ServerNode}}</td>
+<td class="text">{{$row.Direction}}</td>
+<td>{{$row.ParallelStreams}}</td>
+<td>{{result $row}}</td>
+<td class="text{{if eq $row.Verdict "Pass"}} pass{{else if eq $row.Verdict "Fail"}} fail{{end}}">{{$row.Verdict}}</td>
+<td class="text">{{sparkline (history .)}}</td>
+<td class="text">{{time $row.CompletionTime}}</td>
+</tr>
+{{end}}</table>{{end}}
+{{range .Heatmaps}}<h2>{{.Title}}</h2>
+<table>
+<tr><th class="text">client \ server</th>{{range .Grid.Nodes}}<th>{{.}}</th>{{end}}</tr>
+{{$grid := .Grid}}{{range $client := .Grid.Nodes}}<tr><th class="text">{{$client}}</th>{{range $server := $grid.Nodes}}{{cell $grid $client $server}}{{end}}</tr>
+{{end}}</table>
+{{end}}</body>
+</html>
+`))
+
+// heatmapCell renders the grid cell with the background color scaled by throughput or latency
+func heatmapCell(grid *Grid, client, server string) template.HTML {
+	text := template.HTMLEscapeString(grid.Cell(client, server))
+	result, found := grid.Result(client, server)
+	if !found || result.Status != v1alpha1.NetperfPhaseDone {
+		return template.HTML("<td>" + text + "</td>")
+	}
+	min, max := grid.Range()
+	value := grid.value(result)
+	if grid.latency {
+		// lower latency is better, so it gets the green end of the scale
+		value = min + max - value
+	}
+	return template.HTML(fmt.Sprintf(`<td style="background: %s">%s</td>`, heatColor(value, min, max), text))
+}
+
+// sparklineSVG renders the values as an inline SVG polyline
+func sparklineSVG(values []float64) template.HTML {
+	if len(values) < 2 {
+		return ""
+	}
+	var points []string
+	step := float64(sparklineWidth) / float64(len(values)-1)
+	for i, v := range normalize(values) {
+		points = append(points, fmt.Sprintf("%.1f,%.1f", float64(i)*step, (1-v)*(sparklineHeight-2)+1))
+	}
+	return template.HTML(fmt.Sprintf(`<svg width="%d" height="%d"><polyline fill="none" stroke="#1565c0" `+
+		`stroke-width="1.5" points="%s"/></svg>`, sparklineWidth, sparklineHeight, strings.Join(points, " ")))
+}
+
+func writeHTML(w io.Writer, r *Report) error {
+	return htmlTemplate.Execute(w, struct {
+		*Report
+		Summary  Summary
+		Heatmaps []section
+	}{r, r.Summary(), r.heatmaps()})
+}
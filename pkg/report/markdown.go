@@ -0,0 +1,76 @@
+package report
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/piontec/netperf-operator/pkg/apis/app/v1alpha1"
+)
+
+var markdownEscaper = strings.NewReplacer("|", `\|`)
+
+// markdownVerdict marks failures, so they stand out in the table
+func markdownVerdict(netperf v1alpha1.Netperf, row Row) string {
+	if isFailed(netperf) {
+		return "**FAIL**"
+	}
+	return row.Verdict
+}
+
+func writeMarkdownGrid(w io.Writer, s section) {
+	fmt.Fprintf(w, "## %s\n\n| client \\ server |", s.Title)
+	for _, node := range s.Grid.Nodes {
+		fmt.Fprintf(w, " %s |", markdownEscaper.Replace(node))
+	}
+	fmt.Fprintf(w, "\n|---|%s\n", strings.Repeat("---:|", len(s.Grid.Nodes)))
+	for _, client := range s.Grid.Nodes {
+		fmt.Fprintf(w, "| **%s** |", markdownEscaper.Replace(client))
+		for _, server := range s.Grid.Nodes {
+			fmt.Fprintf(w, " %s |", s.Grid.Cell(client, server))
+		}
+		fmt.Fprintln(w)
+	}
+	fmt.Fprintln(w)
+}
+
+// errWriter stops writing after the first error, so that it's checked only once at the end
+type errWriter struct {
+	w   io.Writer
+	err error
+}
+
+func (ew *errWriter) Write(p []byte) (int, error) {
+	if ew.err != nil {
+		return 0, ew.err
+	}
+	n, err := ew.w.Write(p)
+	ew.err = err
+	return n, err
+}
+
+func writeMarkdown(out io.Writer, r *Report) error {
+	w := &errWriter{w: out}
+	fmt.Fprintf(w, "# %s\n\nGenerated %s\n\n", r.Title, formatTime(&r.Generated))
+	s := r.Summary()
+	fmt.Fprintf(w, "**Tests:** %d, **passed:** %d, **failed:** %d, **running:** %d\n\n", s.Total, s.Passed,
+		s.Failed, s.Running)
+
+	if len(r.Netperfs) > 0 {
+		fmt.Fprint(w, "## Tests\n\n")
+		fmt.Fprintln(w, "| Name | Status | Client | Server | Direction | Streams | Result | Verdict | History | Completed |")
+		fmt.Fprintln(w, "|---|---|---|---|---|---:|---:|---|---|---|")
+		for _, netperf := range r.Netperfs {
+			row := NewRow(netperf)
+			fmt.Fprintf(w, "| %s | %s | %s | %s | %s | %d | %s | %s | %s | %s |\n",
+				markdownEscaper.Replace(row.Name), row.Status, row.ClientNode, row.ServerNode, row.Direction,
+				row.ParallelStreams, doneResult(row), markdownVerdict(netperf, row),
+				Sparkline(historyResults(netperf)), formatTime(row.CompletionTime))
+		}
+		fmt.Fprintln(w)
+	}
+	for _, s := range r.heatmaps() {
+		writeMarkdownGrid(w, s)
+	}
+	return w.err
+}
@@ -0,0 +1,112 @@
+package report
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"sort"
+	"text/tabwriter"
+
+	"github.com/piontec/netperf-operator/pkg/apis/app/v1alpha1"
+)
+
+// Grid has results of a NetperfMatrix indexed by client and server node
+type Grid struct {
+	Nodes   []string
+	results map[string]map[string]v1alpha1.NetperfMatrixResult
+	// latency is true if the tests measure the 99th percentile latency instead of throughput
+	latency bool
+}
+
+func NewGrid(matrix *v1alpha1.NetperfMatrix) *Grid {
+	grid := &Grid{
+		Nodes:   append([]string{}, matrix.Status.Nodes...),
+		results: map[string]map[string]v1alpha1.NetperfMatrixResult{},
+		latency: !matrix.Spec.Template.MeasuresThroughput(),
+	}
+	known := map[string]bool{}
+	for _, node := range grid.Nodes {
+		known[node] = true
+	}
+	for _, result := range matrix.Status.Results {
+		for _, node := range []string{result.ClientNode, result.ServerNode} {
+			if !known[node] {
+				known[node] = true
+				grid.Nodes = append(grid.Nodes, node)
+			}
+		}
+		if grid.results[result.ClientNode] == nil {
+			grid.results[result.ClientNode] = map[string]v1alpha1.NetperfMatrixResult{}
+		}
+		grid.results[result.ClientNode][result.ServerNode] = result
+	}
+	sort.Strings(grid.Nodes)
+	return grid
+}
+
+// Result returns the result of the test from the client to the server node
+func (g *Grid) Result(client, server string) (v1alpha1.NetperfMatrixResult, bool) {
+	result, found := g.results[client][server]
+	return result, found
+}
+
+// value returns the measured throughput or latency of the result
+func (g *Grid) value(result v1alpha1.NetperfMatrixResult) float64 {
+	if g.latency {
+		return result.P99LatencyMicroseconds
+	}
+	return result.SpeedBitsPerSec
+}
+
+// Cell formats the result for the grid: the throughput (or latency) of finished tests, status
+// otherwise and "-" for pairs that weren't tested
+func (g *Grid) Cell(client, server string) string {
+	result, found := g.Result(client, server)
+	switch {
+	case !found:
+		return "-"
+	case result.Status == v1alpha1.NetperfPhaseDone && g.latency:
+		return formatLatency(result.P99LatencyMicroseconds)
+	case result.Status == v1alpha1.NetperfPhaseDone:
+		return formatSpeed(result.SpeedBitsPerSec)
+	case result.Status == v1alpha1.NetperfPhaseError:
+		return "error"
+	default:
+		return "..."
+	}
+}
+
+// WriteMatrix writes the grid of results with client nodes in rows and server nodes in columns
+func WriteMatrix(w io.Writer, format string, matrix *v1alpha1.NetperfMatrix) error {
+	grid := NewGrid(matrix)
+	header := append([]string{"CLIENT \\ SERVER"}, grid.Nodes...)
+	var lines [][]string
+	for _, client := range grid.Nodes {
+		line := []string{client}
+		for _, server := range grid.Nodes {
+			line = append(line, grid.Cell(client, server))
+		}
+		lines = append(lines, line)
+	}
+
+	switch format {
+	case FormatTable, "":
+		tw := tabwriter.NewWriter(w, 0, 8, 3, ' ', 0)
+		for _, line := range append([][]string{header}, lines...) {
+			for i, cell := range line {
+				if i > 0 {
+					fmt.Fprint(tw, "\t")
+				}
+				fmt.Fprint(tw, cell)
+			}
+			fmt.Fprintln(tw)
+		}
+		return tw.Flush()
+	case FormatCSV:
+		header[0] = "client"
+		cw := csv.NewWriter(w)
+		cw.WriteAll(append([][]string{header}, lines...))
+		return cw.Error()
+	}
+	return fmt.Errorf("unknown format %q", format)
+}
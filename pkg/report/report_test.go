@@ -0,0 +1,164 @@
+package report
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/piontec/netperf-operator/pkg/apis/app/v1alpha1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+var testNetperfs = []v1alpha1.Netperf{
+	{
+		ObjectMeta: metav1.ObjectMeta{Name: "done", Namespace: "default"},
+		Spec:       v1alpha1.NetperfSpec{ServerNode: "node-1", ClientNode: "node-2"},
+		Status: v1alpha1.NetperfStatus{
+			Status:          v1alpha1.NetperfPhaseDone,
+			SpeedBitsPerSec: 9000.5,
+			Verdict:         v1alpha1.NetperfVerdictPass,
+		},
+	},
+	{
+		ObjectMeta: metav1.ObjectMeta{Name: "pending", Namespace: "default"},
+		Spec:       v1alpha1.NetperfSpec{ServerNode: "node-2", ClientNode: "node-1", ParallelStreams: 4},
+	},
+	{
+		ObjectMeta: metav1.ObjectMeta{Name: "latency", Namespace: "default"},
+		Spec:       v1alpha1.NetperfSpec{Backend: v1alpha1.NetperfBackendLatency},
+		Status: v1alpha1.NetperfStatus{
+			Status: v1alpha1.NetperfPhaseDone,
+			Stats:  &v1alpha1.NetperfStats{P99LatencyMicroseconds: 120},
+		},
+	},
+}
+
+func TestWriteResults(t *testing.T) {
+	tests := []struct {
+		format string
+		want   []string
+	}{
+		{FormatTable, []string{"NAME ", "done ", "9000.50 Mbit/s", "Pass", "pending ", "Pending", "p99 120.00us"}},
+		{FormatCSV, []string{"namespace,name,status", "default,done,Done,0,node-2,node-1,clientToServer,1,9000.50",
+			"default,latency,Done,0,,,clientToServer,1,,,,120.00,,"}},
+		{FormatJSON, []string{`"name": "pending"`, `"parallelStreams": 4`}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.format, func(t *testing.T) {
+			var out bytes.Buffer
+			if err := WriteResults(&out, tt.format, testNetperfs); err != nil {
+				t.Fatalf("WriteResults() error = %v", err)
+			}
+			for _, want := range tt.want {
+				if !strings.Contains(out.String(), want) {
+					t.Errorf("WriteResults() = %s, want it to contain %q", out.String(), want)
+				}
+			}
+		})
+	}
+}
+
+func TestWriteMatrix(t *testing.T) {
+	matrix := &v1alpha1.NetperfMatrix{
+		Status: v1alpha1.NetperfMatrixStatus{
+			Nodes: []string{"b", "a"},
+			Results: []v1alpha1.NetperfMatrixResult{
+				{ClientNode: "a", ServerNode: "b", Status: v1alpha1.NetperfPhaseDone, SpeedBitsPerSec: 100},
+				{ClientNode: "b", ServerNode: "a", Status: v1alpha1.NetperfPhaseError},
+			},
+		},
+	}
+	var out bytes.Buffer
+	if err := WriteMatrix(&out, FormatCSV, matrix); err != nil {
+		t.Fatalf("WriteMatrix() error = %v", err)
+	}
+	want := "client,a,b\na,-,100.00 Mbit/s\nb,error,-\n"
+	if out.String() != want {
+		t.Errorf("WriteMatrix() = %q, want %q", out.String(), want)
+	}
+
+	matrix.Spec.Template.Backend = v1alpha1.NetperfBackendLatency
+	matrix.Status.Results[0].P99LatencyMicroseconds = 250
+	out.Reset()
+	if err := WriteMatrix(&out, FormatCSV, matrix); err != nil {
+		t.Fatalf("WriteMatrix() error = %v", err)
+	}
+	want = "client,a,b\na,-,250.00us\nb,error,-\n"
+	if out.String() != want {
+		t.Errorf("WriteMatrix() = %q, want %q", out.String(), want)
+	}
+}
+
+func TestReport_Write(t *testing.T) {
+	history := []v1alpha1.NetperfRunResult{
+		{Status: v1alpha1.NetperfPhaseDone, SpeedBitsPerSec: 100},
+		{Status: v1alpha1.NetperfPhaseDone, SpeedBitsPerSec: 200},
+	}
+	netperfs := []v1alpha1.Netperf{
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "a-b"},
+			Status: v1alpha1.NetperfStatus{Status: v1alpha1.NetperfPhaseDone, ServerNode: "b", ClientNode: "a",
+				SpeedBitsPerSec: 200, History: history},
+		},
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "b-a"},
+			Status: v1alpha1.NetperfStatus{Status: v1alpha1.NetperfPhaseDone, ServerNode: "a", ClientNode: "b",
+				SpeedBitsPerSec: 100, Verdict: v1alpha1.NetperfVerdictFail},
+		},
+	}
+	tests := []struct {
+		format string
+		want   []string
+	}{
+		{FormatMarkdown, []string{"**passed:** 1, **failed:** 1", "| a-b | Done | a | b |", "▁█", "**FAIL**",
+			"| **a** | - | 200.00 Mbit/s |"}},
+		{FormatHTML, []string{"<polyline", `<td style="background: hsl(120, 70%, 75%)">200.00 Mbit/s</td>`,
+			`class="text fail">Fail`}},
+		{FormatCSV, []string{"namespace,name,status", ",a-b,Done,0,a,b,clientToServer,1,200.00"}},
+		{FormatJSON, []string{`"name": "b-a"`, `"verdict": "Fail"`}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.format, func(t *testing.T) {
+			r := &Report{Title: "Test", Netperfs: netperfs}
+			var out bytes.Buffer
+			if err := r.Write(&out, tt.format); err != nil {
+				t.Fatalf("Report.Write() error = %v", err)
+			}
+			for _, want := range tt.want {
+				if !strings.Contains(out.String(), want) {
+					t.Errorf("Report.Write() = %s, want it to contain %q", out.String(), want)
+				}
+			}
+		})
+	}
+}
+
+func TestReport_Write_running(t *testing.T) {
+	r := &Report{Title: "Test", Netperfs: []v1alpha1.Netperf{{
+		ObjectMeta: metav1.ObjectMeta{Name: "rerun"},
+		Status:     v1alpha1.NetperfStatus{Status: v1alpha1.NetperfPhaseTest, SpeedBitsPerSec: 300},
+	}}}
+	for _, format := range []string{FormatMarkdown, FormatHTML} {
+		var out bytes.Buffer
+		if err := r.Write(&out, format); err != nil {
+			t.Fatalf("Report.Write() error = %v", err)
+		}
+		if strings.Contains(out.String(), "300.00") {
+			t.Errorf("Report.Write() = %s, want no result of the running test", out.String())
+		}
+	}
+}
+
+type failingWriter struct{}
+
+func (failingWriter) Write(p []byte) (int, error) {
+	return 0, errors.New("disk full")
+}
+
+func TestReport_Write_error(t *testing.T) {
+	r := &Report{Title: "Test", Netperfs: testNetperfs}
+	if err := r.Write(failingWriter{}, FormatMarkdown); err == nil || err.Error() != "disk full" {
+		t.Errorf("Report.Write() error = %v, want the write error", err)
+	}
+}
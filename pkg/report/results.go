@@ -0,0 +1,184 @@
+// Package report formats results of Netperf tests
+package report
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"text/tabwriter"
+	"time"
+
+	"github.com/piontec/netperf-operator/pkg/apis/app/v1alpha1"
+)
+
+const (
+	FormatTable = "table"
+	FormatJSON  = "json"
+	FormatCSV   = "csv"
+)
+
+// Row is the result of the last run of a Netperf
+type Row struct {
+	Namespace                string     `json:"namespace"`
+	Name                     string     `json:"name"`
+	Status                   string     `json:"status"`
+	Run                      int        `json:"run"`
+	ServerNode               string     `json:"serverNode"`
+	ClientNode               string     `json:"clientNode"`
+	Direction                string     `json:"direction"`
+	ParallelStreams          int        `json:"parallelStreams"`
+	SpeedBitsPerSec          float64    `json:"speedBitsPerSec"`
+	ClientToServerBitsPerSec float64    `json:"clientToServerBitsPerSec"`
+	ServerToClientBitsPerSec float64    `json:"serverToClientBitsPerSec"`
+	P99LatencyMicroseconds   float64    `json:"p99LatencyMicroseconds,omitempty"`
+	PathMTU                  int        `json:"pathMTU,omitempty"`
+	Verdict                  string     `json:"verdict,omitempty"`
+	CompletionTime           *time.Time `json:"completionTime,omitempty"`
+	// measuresThroughput is false for the backends measuring latency or the path MTU
+	measuresThroughput bool
+}
+
+func NewRow(netperf v1alpha1.Netperf) Row {
+	row := Row{
+		Namespace:                netperf.Namespace,
+		Name:                     netperf.Name,
+		Status:                   netperf.Status.Status,
+		Run:                      netperf.Status.Run,
+		ServerNode:               netperf.Status.ServerNode,
+		ClientNode:               netperf.Status.ClientNode,
+		Direction:                netperf.Spec.Direction,
+		ParallelStreams:          netperf.Spec.ParallelStreams,
+		SpeedBitsPerSec:          netperf.Status.SpeedBitsPerSec,
+		ClientToServerBitsPerSec: netperf.Status.ClientToServerBitsPerSec,
+		ServerToClientBitsPerSec: netperf.Status.ServerToClientBitsPerSec,
+		Verdict:                  netperf.Status.Verdict,
+		measuresThroughput:       netperf.Spec.MeasuresThroughput(),
+	}
+	if netperf.Status.Stats != nil {
+		row.P99LatencyMicroseconds = netperf.Status.Stats.P99LatencyMicroseconds
+	}
+	if netperf.Status.MTU != nil {
+		row.PathMTU = netperf.Status.MTU.PathMTU
+	}
+	if row.ServerNode == "" {
+		row.ServerNode = netperf.Spec.ServerNode
+	}
+	if row.ClientNode == "" {
+		row.ClientNode = netperf.Spec.ClientNode
+	}
+	if row.Direction == "" {
+		row.Direction = v1alpha1.NetperfDirectionClientToServer
+	}
+	if row.ParallelStreams < 1 {
+		row.ParallelStreams = 1
+	}
+	if row.Status == v1alpha1.NetperfPhaseInitial {
+		row.Status = "Pending"
+	}
+	if netperf.Status.CompletionTime != nil {
+		completion := netperf.Status.CompletionTime.Time
+		row.CompletionTime = &completion
+	}
+	return row
+}
+
+// Result formats the main result of the test: throughput, the path MTU or the 99th percentile
+// latency, depending on the backend
+func (r Row) Result() string {
+	switch {
+	case r.measuresThroughput:
+		return formatSpeed(r.SpeedBitsPerSec)
+	case r.PathMTU > 0:
+		return "mtu " + strconv.Itoa(r.PathMTU)
+	default:
+		return "p99 " + formatLatency(r.P99LatencyMicroseconds)
+	}
+}
+
+// doneResult returns the result of a finished test, results of other tests can't be trusted
+func doneResult(r Row) string {
+	if r.Status != v1alpha1.NetperfPhaseDone {
+		return ""
+	}
+	return r.Result()
+}
+
+func newRows(netperfs []v1alpha1.Netperf) []Row {
+	rows := make([]Row, 0, len(netperfs))
+	for _, netperf := range netperfs {
+		rows = append(rows, NewRow(netperf))
+	}
+	return rows
+}
+
+// WriteResults writes results of the Netperf objects in the format
+func WriteResults(w io.Writer, format string, netperfs []v1alpha1.Netperf) error {
+	switch format {
+	case FormatTable, "":
+		return writeTable(w, newRows(netperfs))
+	case FormatJSON:
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(newRows(netperfs))
+	case FormatCSV:
+		return writeCSV(w, newRows(netperfs))
+	}
+	return fmt.Errorf("unknown format %q", format)
+}
+
+// formatSpeed formats the throughput, that is stored in 10^6 bits per second
+func formatSpeed(speed float64) string {
+	return strconv.FormatFloat(speed, 'f', 2, 64) + " Mbit/s"
+}
+
+func formatLatency(microseconds float64) string {
+	return strconv.FormatFloat(microseconds, 'f', 2, 64) + "us"
+}
+
+// formatOptional formats the value without unit, as it isn't measured by all the backends
+func formatOptional(value float64, measured bool) string {
+	if !measured {
+		return ""
+	}
+	return strconv.FormatFloat(value, 'f', 2, 64)
+}
+
+func formatTime(t *time.Time) string {
+	if t == nil {
+		return ""
+	}
+	return t.UTC().Format(time.RFC3339)
+}
+
+func writeTable(w io.Writer, rows []Row) error {
+	tw := tabwriter.NewWriter(w, 0, 8, 3, ' ', 0)
+	fmt.Fprintln(tw, "NAME\tSTATUS\tRUN\tCLIENT\tSERVER\tDIRECTION\tSTREAMS\tRESULT\tVERDICT\tCOMPLETED")
+	for _, r := range rows {
+		fmt.Fprintf(tw, "%s\t%s\t%d\t%s\t%s\t%s\t%d\t%s\t%s\t%s\n", r.Name, r.Status, r.Run, r.ClientNode,
+			r.ServerNode, r.Direction, r.ParallelStreams, doneResult(r), r.Verdict, formatTime(r.CompletionTime))
+	}
+	return tw.Flush()
+}
+
+func writeCSV(w io.Writer, rows []Row) error {
+	cw := csv.NewWriter(w)
+	cw.Write([]string{"namespace", "name", "status", "run", "client_node", "server_node", "direction",
+		"parallel_streams", "speed_mbits_per_sec", "client_to_server_mbits_per_sec", "server_to_client_mbits_per_sec",
+		"p99_latency_us", "path_mtu", "verdict", "completion_time"})
+	for _, r := range rows {
+		mtu := ""
+		if r.PathMTU > 0 {
+			mtu = strconv.Itoa(r.PathMTU)
+		}
+		cw.Write([]string{r.Namespace, r.Name, r.Status, strconv.Itoa(r.Run), r.ClientNode, r.ServerNode,
+			r.Direction, strconv.Itoa(r.ParallelStreams), formatOptional(r.SpeedBitsPerSec, r.measuresThroughput),
+			formatOptional(r.ClientToServerBitsPerSec, r.measuresThroughput),
+			formatOptional(r.ServerToClientBitsPerSec, r.measuresThroughput),
+			formatOptional(r.P99LatencyMicroseconds, r.P99LatencyMicroseconds > 0), mtu, r.Verdict,
+			formatTime(r.CompletionTime)})
+	}
+	cw.Flush()
+	return cw.Error()
+}
@@ -6,6 +6,7 @@ import (
 	"github.com/operator-framework/operator-sdk/pkg/sdk"
 
 	"github.com/piontec/netperf-operator/pkg/apis/app/v1alpha1"
+	"github.com/piontec/netperf-operator/pkg/metrics"
 	operator "github.com/piontec/netperf-operator/pkg/netperf-operator"
 
 	"github.com/sirupsen/logrus"
@@ -26,12 +27,25 @@ func (h *Handler) Handle(ctx context.Context, event sdk.Event) error {
 	switch event.Object.(type) {
 	case *v1alpha1.Netperf:
 		netperf := event.Object.(*v1alpha1.Netperf)
-		return h.operator.HandleNetperf(netperf, event.Deleted)
+		return countError("Netperf", h.operator.HandleNetperf(netperf, event.Deleted))
+	case *v1alpha1.NetperfMatrix:
+		matrix := event.Object.(*v1alpha1.NetperfMatrix)
+		return countError("NetperfMatrix", h.operator.HandleNetperfMatrix(matrix, event.Deleted))
+	case *v1alpha1.NetperfSchedule:
+		schedule := event.Object.(*v1alpha1.NetperfSchedule)
+		return countError("NetperfSchedule", h.operator.HandleNetperfSchedule(schedule, event.Deleted))
 	case *v1.Pod:
 		pod := event.Object.(*v1.Pod)
-		return h.operator.HandlePod(pod, event.Deleted)
+		return countError("Pod", h.operator.HandlePod(pod, event.Deleted))
 	default:
 		logrus.Warnf("unknown event received: %s", event)
 	}
 	return nil
 }
+
+func countError(kind string, err error) error {
+	if err != nil {
+		metrics.ReconcileError(kind)
+	}
+	return err
+}
@@ -16,3 +16,5 @@ REPO_PATH="github.com/piontec/netperf-operator"
 BUILD_PATH="${REPO_PATH}/cmd/${PROJECT_NAME}"
 echo "building "${PROJECT_NAME}"..."
 GOOS=linux GOARCH=amd64 CGO_ENABLED=0 go build -o ${BIN_DIR}/${PROJECT_NAME} $BUILD_PATH
+echo "building kubectl-netperf..."
+GOOS=linux GOARCH=amd64 CGO_ENABLED=0 go build -o ${BIN_DIR}/kubectl-netperf ${REPO_PATH}/cmd/kubectl-netperf